@@ -0,0 +1,210 @@
+package pkg
+
+import (
+	"reflect"
+
+	"github.com/4strodev/wiring/pkg/errors"
+)
+
+// ErrFrozen is returned when registering dependencies on a container that has been built
+var ErrFrozen = errors.NewError("container is built, no more dependencies can be registered")
+
+// resolverPlan is the precomputed way of calling a resolver. Once the container is built
+// the dependencies of every resolver are known so they are looked up just once.
+type resolverPlan struct {
+	resolver  reflect.Value
+	arguments []plannedArgument
+}
+
+// plannedDependency is a dependency already bound to the spec or group that resolves it.
+// Optional dependencies that are not registered are bound to nothing.
+type plannedDependency struct {
+	key       string
+	isContext bool
+	spec      *dependencySpec
+	group     *dependencyGroup
+	sliceType reflect.Type
+	// deferred is set for dependencies requested through a Lazy or a provider
+	deferred *dependency
+}
+
+// plannedArgument is an argument of a resolver. Parameter objects have their fields planned.
+type plannedArgument struct {
+	plannedDependency
+	paramsType reflect.Type
+	fields     []plannedField
+}
+
+type plannedField struct {
+	index     int
+	name      string
+	fieldType reflect.Type
+	// fill fields are filled recursively when the resolver is called
+	fill bool
+	plannedDependency
+}
+
+// Build implements pkg.Container.
+func (w *wireContainer) Build() error {
+	if w.root != nil {
+		return ErrScopeRegistration
+	}
+
+	w.registryMutex.Lock()
+	defer w.registryMutex.Unlock()
+	if w.frozen.Load() {
+		return nil
+	}
+	err := w.Validate()
+	if err != nil {
+		return err
+	}
+
+	snapshot := w.snapshot()
+	for _, spec := range snapshot.specs() {
+		spec.plan.Store(snapshot.plan(spec))
+	}
+	w.frozen.Store(true)
+	return nil
+}
+
+// specs returns every spec of the registry
+func (r *registry) specs() []*dependencySpec {
+	specs := make([]*dependencySpec, 0, len(r.typeMapping)+len(r.tokenMapping))
+	for _, spec := range r.typeMapping {
+		specs = append(specs, spec)
+	}
+	for _, spec := range r.tokenMapping {
+		specs = append(specs, spec)
+	}
+	for _, group := range r.groupMapping {
+		for _, member := range group.members {
+			specs = append(specs, member.spec)
+		}
+	}
+	return specs
+}
+
+// plan computes the resolver plan of the spec. The registry must have been validated.
+// Specs of structs have no resolver so they are not planned.
+func (r *registry) plan(spec *dependencySpec) *resolverPlan {
+	if spec.structType != nil {
+		return nil
+	}
+
+	resolverType := reflect.TypeOf(spec.resolver)
+	plan := &resolverPlan{
+		resolver:  reflect.ValueOf(spec.resolver),
+		arguments: make([]plannedArgument, resolverType.NumIn()),
+	}
+
+	for i := range plan.arguments {
+		inType := resolverType.In(i)
+		if !isParameterObject(inType) {
+			plan.arguments[i].plannedDependency = r.planDependency(newDependency(inType, ""))
+			continue
+		}
+
+		plan.arguments[i].paramsType = inType
+		for _, field := range wiredFields(inType) {
+			planned := plannedField{
+				index:     field.index,
+				name:      field.name,
+				fieldType: inType.Field(field.index).Type,
+				fill:      field.filled(r),
+			}
+			if !planned.fill {
+				planned.plannedDependency = r.planDependency(field.dependency)
+			}
+			plan.arguments[i].fields = append(plan.arguments[i].fields, planned)
+		}
+	}
+	return plan
+}
+
+func (r *registry) planDependency(dep dependency) plannedDependency {
+	dep = r.resolvedDependency(dep)
+	planned := plannedDependency{key: dep.key()}
+	switch {
+	case dep.deferredType != nil:
+		planned.deferred = &dep
+	case dep.token != "":
+		planned.spec = r.tokenMapping[dep.token]
+	case dep.refType == contextType:
+		planned.isContext = true
+	default:
+		group, isGroup := r.getGroup(dep.refType)
+		if isGroup {
+			planned.group = group
+			planned.sliceType = dep.refType
+		} else {
+			planned.spec = r.typeMapping[dep.refType]
+		}
+	}
+	return planned
+}
+
+// missing reports if the dependency is optional and it is not registered
+func (planned *plannedDependency) missing() bool {
+	return !planned.isContext && planned.group == nil && planned.spec == nil && planned.deferred == nil
+}
+
+func (planned *plannedDependency) resolve(res *resolution) (reflect.Value, error) {
+	var instance any
+	var err error
+	switch {
+	case planned.isContext:
+		return reflect.ValueOf(&res.ctx).Elem(), nil
+	case planned.deferred != nil:
+		return reflect.ValueOf(newDeferred(*planned.deferred, res)), nil
+	case planned.group != nil:
+		instance, err = planned.group.resolve(res, planned.sliceType)
+	default:
+		instance, err = planned.spec.resolve(res, planned.key)
+	}
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(instance), nil
+}
+
+// call executes the resolver resolving its arguments following the plan
+func (plan *resolverPlan) call(res *resolution) ([]reflect.Value, error) {
+	values := make([]reflect.Value, len(plan.arguments))
+	for i := range plan.arguments {
+		argument := &plan.arguments[i]
+		if argument.paramsType == nil {
+			value, err := argument.resolve(res)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+			continue
+		}
+
+		params := reflect.New(argument.paramsType).Elem()
+		for j := range argument.fields {
+			field := &argument.fields[j]
+			if field.fill {
+				err := res.container.fillNested(params.Field(field.index), field.name, res, []reflect.Type{argument.paramsType})
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if field.missing() {
+				continue
+			}
+			value, err := field.resolve(res)
+			if err != nil {
+				return nil, errors.Errorf("error resolving field '%s': %w", field.name, err)
+			}
+			if !value.IsValid() || !value.Type().AssignableTo(field.fieldType) {
+				return nil, errors.Errorf("error resolving field '%s': wrong resolver for type %v", field.name, field.fieldType).WithKind(errors.ErrWrongType).WithKey(field.key)
+			}
+			params.Field(field.index).Set(value)
+		}
+		values[i] = params
+	}
+	return plan.resolver.Call(values), nil
+}
@@ -0,0 +1,102 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+
+	"github.com/4strodev/wiring/pkg/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func newBuildContainer(t testing.TB) Container {
+	container := New()
+	require.NoError(t, container.Singleton(mocks.Resolver))
+	require.NoError(t, container.SingletonToken("primaryDB", func() *database {
+		return &database{name: "primary"}
+	}))
+	require.NoError(t, container.SingletonToken("replicaDB", func() *database {
+		return &database{name: "replica"}
+	}))
+	require.NoError(t, container.TransientGroup("db", func() healthChecker {
+		return namedChecker("db")
+	}))
+	require.NoError(t, container.Transient(func(ctx context.Context, params repositoryParams, checkers []healthChecker) *repository {
+		return &repository{primary: params.Primary, replica: params.Replica}
+	}))
+	require.NoError(t, container.Transient(func(abstraction mocks.Abstraction, repo *repository) ComplexAbstraction {
+		return ComplexImplementation{Abstraction: abstraction}
+	}))
+	return container
+}
+
+func TestBuild(t *testing.T) {
+	t.Run("should resolve using the compiled plans", func(t *testing.T) {
+		container := newBuildContainer(t)
+		require.NoError(t, container.Build())
+		require.NoError(t, container.Build())
+
+		var repo *repository
+		require.NoError(t, container.Resolve(&repo))
+		require.Equal(t, "primary", repo.primary.name)
+		require.Equal(t, "replica", repo.replica.name)
+
+		var complexAbstraction ComplexAbstraction
+		require.NoError(t, container.NewScope().Resolve(&complexAbstraction))
+		require.NotNil(t, complexAbstraction.(ComplexImplementation).Abstraction)
+	})
+	t.Run("should reject registrations once built", func(t *testing.T) {
+		container := newBuildContainer(t)
+		require.NoError(t, container.Build())
+		require.ErrorIs(t, container.Singleton(mocks.Resolver), ErrFrozen)
+		require.ErrorIs(t, container.ReplaceToken("primaryDB", mocks.TokenResolver), ErrFrozen)
+		require.ErrorIs(t, container.SingletonGroup("other", mocks.Resolver), ErrFrozen)
+	})
+	t.Run("should not build an invalid container", func(t *testing.T) {
+		container := New()
+		require.NoError(t, container.Singleton(func(*cyclicRepo) *cyclicDB {
+			return &cyclicDB{}
+		}))
+		require.NoError(t, container.Singleton(func(*cyclicDB) *cyclicRepo {
+			return &cyclicRepo{}
+		}))
+		require.Error(t, container.Build())
+
+		// The container can still be fixed
+		require.NoError(t, container.Replace(func() *cyclicRepo {
+			return &cyclicRepo{}
+		}))
+		require.NoError(t, container.Build())
+	})
+	t.Run("should abort built resolutions when the context is cancelled", func(t *testing.T) {
+		container := newBuildContainer(t)
+		require.NoError(t, container.Build())
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var repo *repository
+		require.ErrorIs(t, container.ResolveContext(ctx, &repo), context.Canceled)
+	})
+}
+
+func benchmarkResolve(b *testing.B, build bool) {
+	container := newBuildContainer(b)
+	if build {
+		require.NoError(b, container.Build())
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var complexAbstraction ComplexAbstraction
+		err := container.Resolve(&complexAbstraction)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkResolve(b *testing.B) {
+	benchmarkResolve(b, false)
+}
+
+func BenchmarkResolveBuilt(b *testing.B) {
+	benchmarkResolve(b, true)
+}
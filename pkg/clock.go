@@ -0,0 +1,28 @@
+package pkg
+
+import "time"
+
+// Clock tells the time to the container. Replace it with [WithClock] to control the
+// time in tests.
+type Clock interface {
+	Now() time.Time
+	// After waits for the duration like [time.After]
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// WithClock sets the clock used by the container, by default the system clock is used
+func WithClock(clock Clock) Option {
+	return func(w *wireContainer) {
+		w.clock = clock
+	}
+}
@@ -1,43 +1,154 @@
 // contains the wire implementation of interfaces defined on pkg
 package pkg
 
-import "reflect"
+import (
+	"context"
+	"reflect"
+
+	"github.com/4strodev/wiring/pkg/errors"
+)
 
 const WIRE_TAG = "wire"
 
+// ErrClosed is returned when resolving dependencies from a closed container
+var ErrClosed = errors.NewError("container is closed")
+
+// Shutdowner is implemented by singletons that need a context to release their resources.
+// When a singleton implements both [Shutdowner] and [io.Closer] only Shutdown is called.
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
 type Container interface {
 	// Type based injection
 
 	// Singleton sets a dependency as a [wiring.] dependency.
 	// Once the abstraction is instanciated this instance will be cached and
-	// will no longer create new instances
-	Singleton(resolver any) error
+	// will no longer create new instances. The options configure the registration, like [As] or [Eager]
+	Singleton(resolver any, options ...RegistrationOption) error
 	// Transient sets a dependency as a transient dependency.
 	// Every time the container is asked to resolve an abstraction
 	// the container will create a new instance of that dependency
-	Transient(resolver any) error
+	Transient(resolver any, options ...RegistrationOption) error
+	// Scoped sets a dependency as a scoped dependency.
+	// The abstraction can only be resolved from a scope created with NewScope, every
+	// scope caches its own instance. Dependencies of scoped dependencies are resolved inside the scope.
+	Scoped(resolver any, options ...RegistrationOption) error
+	// Replace replaces the resolver of the type returned by the resolver keeping its lifecycle.
+	// Use it to override a dependency intentionally, like test doubles, regardless of the
+	// duplicate policy. It returns an error if the type has no resolver or if the new resolver
+	// does not return a type assignable to every type bound to the previous one.
+	Replace(resolver any) error
+	// Swap replaces the resolver of the type returned by the resolver at runtime, even on a built container,
+	// keeping its lifecycle and registration options. Resolutions already running finish with the previous
+	// resolver. Every key bound to the previous resolver is swapped and notified to the subscribers, the
+	// previous singleton instance is closed once the grace period set with [WithGracePeriod] is over.
+	// Like Replace, the new resolver must return a type assignable to every key it is bound to.
+	Swap(resolver any) error
+	// SingletonStruct sets a singleton dependency built by the container. The type must be a struct or
+	// a struct pointer, it is allocated and its fields are filled following the same rules as Fill.
+	// If the struct implements [Initializer] or [PostConstructor] the hook is called once it is filled.
+	// Use the [As] option to bind it to the interfaces it implements.
+	SingletonStruct(refType reflect.Type, options ...RegistrationOption) error
+	// TransientStruct same as SingletonStruct but with a transient lifecycle
+	TransientStruct(refType reflect.Type, options ...RegistrationOption) error
+	// Decorate adds a decorator to the type it receives. A decorator is a function like
+	// func(T, dependencies...) (T, error) that wraps the instances of T, its dependencies are resolved
+	// like the ones of a resolver. Decorators are applied in registration order every time T is
+	// resolved, singleton and scoped instances are cached once decorated.
+	Decorate(decorator any) error
+	// Invalidate discards the instance of a singleton so it is rebuilt on the next resolution, or right
+	// away in the background when its [RefreshPolicy] says so. The previous instance is closed once replaced.
+	// Dependents registered with [FollowRefresh] are rebuilt as well.
+	Invalidate(refType reflect.Type) error
 	// Resolve given a pointer to value it will be resolved and the container
 	// will update the referenced value with the instance resolved
 	Resolve(value any) error
+	// ResolveContext same as Resolve but the context is injected in every resolver of the chain
+	// that expects a [context.Context]. If the context is cancelled the resolution is aborted.
+	// Keep in mind that singletons are cached so they keep the context they were created with.
+	ResolveContext(ctx context.Context, value any) error
+
+	// Group based injection
+
+	// SingletonGroup adds a singleton dependency to the group of the type returned by the resolver.
+	// Resolving a slice of that type, directly or as a resolver parameter, returns the instances of
+	// every member in registration order. Registering a member with an existing name replaces it.
+	SingletonGroup(name string, resolver any) error
+	// TransientGroup same as SingletonGroup but the member has a transient lifecycle
+	TransientGroup(name string, resolver any) error
 
 	// Token based injection
 
 	// SingletonToken same as Singleton but instead of using the type to identify
 	// the implementation it uses the token
-	SingletonToken(token string, resolver any) error
+	SingletonToken(token string, resolver any, options ...RegistrationOption) error
 	// TransientToken same as Transient but instead of using the type to identify
 	// the implementation it uses the token
-	TransientToken(token string, resolver any) error
+	TransientToken(token string, resolver any, options ...RegistrationOption) error
+	// ScopedToken same as Scoped but instead of using the type to identify
+	// the implementation it uses the token
+	ScopedToken(token string, resolver any, options ...RegistrationOption) error
+	// DecorateToken same as Decorate but for token based dependencies
+	DecorateToken(token string, decorator any) error
+	// InvalidateToken same as Invalidate but for token based dependencies
+	InvalidateToken(token string) error
+	// ReplaceToken same as Replace but for token based dependencies
+	ReplaceToken(token string, resolver any) error
+	// SwapToken same as Swap but for token based dependencies
+	SwapToken(token string, resolver any) error
 	// Gets the instance associated with the provided token
 	ResolveToken(token string, value any) error
+	// ResolveTokenContext same as ResolveToken but injecting the context like ResolveContext
+	ResolveTokenContext(ctx context.Context, token string, value any) error
 
 	// Fill gets a struct pointer and resolves their fields, if the field needs to be resolved by token
 	// you can use the 'wire' tag with the token that is associated with. If the field needs to be ignored
-	// use the ignore param -> wire:",ignore". Unexported fields will be ignored
+	// use the ignore param -> wire:",ignore". Unexported fields will be ignored.
+	// Fields tagged with the optional param -> wire:",optional" are left empty if they are not registered.
+	// Fields tagged with the fill param -> wire:",fill" and embedded structs that are not registered are filled recursively,
+	// allocating nil struct pointers
 	Fill(structure any) error
+	// FillContext same as Fill but injecting the context like ResolveContext
+	FillContext(ctx context.Context, structure any) error
 
 	// Check if the container has a resolver for that type
 	HasType(refType reflect.Type) bool
 	// Check if the container has a resolver for that token
 	HasToken(token string) bool
+
+	// Registrations describes every dependency registered on the container, type based
+	// dependencies first sorted by type name, then token based dependencies sorted by token
+	// and finally the members of the groups in registration order.
+	// Use [WriteDOT] or [WriteJSON] to export them.
+	Registrations() []Registration
+
+	// Subscribe calls the subscriber every time a dependency is swapped, so it can resolve it again.
+	// Subscribers are called synchronously once the swap is published. Call the returned function
+	// to stop receiving events.
+	Subscribe(subscriber func(event ChangeEvent)) (unsubscribe func())
+
+	// Validate checks the whole dependency graph without executing any resolver.
+	// It reports every resolver that depends on a missing abstraction and every
+	// circular dependency found. Use it at startup or in a unit test.
+	Validate() error
+
+	// NewScope creates a scope that shares the resolvers of the container. Singletons are still
+	// cached by the container while scoped dependencies are cached by the scope. Dependencies cannot be
+	// registered on a scope. Closing the scope only releases its scoped instances.
+	NewScope() Container
+
+	// Build validates the dependency graph and compiles the way every resolver is called, so resolutions
+	// do far less work. Once built no more dependencies can be registered on the container.
+	Build() error
+
+	// WarmUp instantiates every singleton registered with the [Eager] option so slow resolvers run at
+	// startup instead of on the first use. Independent branches of the graph are instantiated concurrently
+	// and the errors are aggregated by key. If the context is cancelled the remaining singletons are skipped.
+	WarmUp(ctx context.Context) error
+
+	// Close releases every singleton instantiated by the container in reverse creation order.
+	// Singletons implementing [Shutdowner] or [io.Closer] are shut down and the errors returned
+	// are aggregated. Once closed the container refuses to resolve any dependency.
+	Close(ctx context.Context) error
 }
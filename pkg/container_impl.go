@@ -1,61 +1,103 @@
 package pkg
 
 import (
-	"fmt"
+	"context"
+	stderrors "errors"
+	"io"
 	"reflect"
-	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/4strodev/wiring/pkg/errors"
 )
 
-func New() Container {
-	return &wireContainer{
-		typeMapping:  make(map[reflect.Type]*dependencySpec),
-		tokenMapping: make(map[string]*dependencySpec),
+// New creates a container configured with the provided options
+func New(options ...Option) Container {
+	container := &wireContainer{
+		registry:      new(atomic.Pointer[registry]),
+		duplicateHook: defaultDuplicateHook,
+		clock:         systemClock{},
 	}
+	container.registry.Store(newRegistry())
+	for _, option := range options {
+		option(container)
+	}
+	return container
 }
 
 type wireContainer struct {
-	typeMapping  typeMap
-	tokenMapping tokenMap
+	// registry holds the registered dependencies, it is shared with the scopes of the container
+	registry      *atomic.Pointer[registry]
+	registryMutex sync.Mutex
+	// frozen is set once the container is built
+	frozen atomic.Bool
+
+	duplicatePolicy DuplicatePolicy
+	duplicateHook   func(err error)
+	// hooks observe the resolutions of the container and its scopes
+	hooks  hooks
+	tracer Tracer
+	clock  Clock
+	// gracePeriod is the time swapped singletons are kept alive before being closed
+	gracePeriod time.Duration
+	subscribers subscribers
+
+	closed atomic.Bool
+	// instances holds the cached instances in creation order
+	instances      []cachedInstance
+	instancesMutex sync.Mutex
+
+	// root is the container that created the scope, nil if the container is not a scope
+	root *wireContainer
+	// scopedInstances holds the instances of scoped dependencies of the scope
+	scopedInstances map[*dependencySpec]*scopedInstance
+	scopedMutex     sync.Mutex
+}
+
+// cachedInstance is an instance that has been cached by the container
+type cachedInstance struct {
+	spec     *dependencySpec
+	key      string
+	instance any
 }
 
 // HasToken implements Container.
 func (w *wireContainer) HasToken(token string) bool {
-	_, ok := w.tokenMapping[token]
+	_, ok := w.snapshot().tokenMapping[token]
 	return ok
 }
 
 // HasType implements Container.
 func (w *wireContainer) HasType(refType reflect.Type) bool {
-	_, ok := w.typeMapping[refType]
+	snapshot := w.snapshot()
+	_, ok := snapshot.typeMapping[refType]
+	if !ok {
+		_, ok = snapshot.getGroup(refType)
+	}
 	return ok
 }
 
 // SingletonToken implements pkg.Container.
-func (w *wireContainer) SingletonToken(token string, resolver any) error {
-	spec, err := newSpec(resolver, SINGLETON, w)
-	if err != nil {
-		return err
-	}
-	spec.lifeCycle = SINGLETON
-	w.tokenMapping[token] = spec
-	return nil
+func (w *wireContainer) SingletonToken(token string, resolver any, options ...RegistrationOption) error {
+	return w.registerToken(token, resolver, SINGLETON, options)
 }
 
 // TransientToken implements pkg.Container.
-func (w *wireContainer) TransientToken(token string, resolver any) error {
-	spec, err := newSpec(resolver, TRANSIENT, w)
-	if err != nil {
-		return err
-	}
-	spec.lifeCycle = TRANSIENT
-	w.tokenMapping[token] = spec
-	return nil
+func (w *wireContainer) TransientToken(token string, resolver any, options ...RegistrationOption) error {
+	return w.registerToken(token, resolver, TRANSIENT, options)
 }
 
 // Fill implements pkg.Container.
 func (w *wireContainer) Fill(structure any) error {
+	return w.FillContext(context.Background(), structure)
+}
+
+// FillContext implements pkg.Container.
+func (w *wireContainer) FillContext(ctx context.Context, structure any) error {
+	if w.isClosed() {
+		return ErrClosed
+	}
 	baseType := reflect.TypeOf(structure)
 	baseValue := reflect.ValueOf(structure)
 	if baseType.Kind() != reflect.Pointer {
@@ -66,58 +108,19 @@ func (w *wireContainer) Fill(structure any) error {
 		return errors.NewError("fill requires a struct pointer")
 	}
 
-	structType := baseType.Elem()
-	structValue := baseValue.Elem()
-	nFields := structType.NumField()
-	for i := 0; i < nFields; i++ {
-		var instance any
-		var err error
-		fieldType := structType.Field(i)
-		if !fieldType.IsExported() {
-			continue
-		}
-		fieldValue := structValue.Field(i)
-
-		tagValue := fieldType.Tag.Get(WIRE_TAG)
-		tagParams := strings.SplitN(tagValue, ",", 2)
-		if len(tagParams) == 2 && "ignore" == tagParams[1] {
-			continue
-		}
-
-		// Handling token resolved strategy
-		var spec *dependencySpec
-		if tagParams[0] != "" {
-			tokenTag := tagParams[0]
-			spec, err := w.getSpecForToken(tokenTag)
-			if err != nil {
-				return err
-			}
-			instance, err = spec.Resolve()
-			if err != nil {
-				return err
-			}
-			fieldValue.Set(reflect.ValueOf(instance))
-			continue
-		}
-
-		// Handling type resolving strategy
-		spec, err = w.getSpec(fieldType.Type)
-		if err != nil {
-			err = errors.Errorf("error resolving field '%s': %w", fieldType.Name, err)
-			return err
-		}
-		instance, err = spec.Resolve()
-		if err != nil {
-			return nil
-		}
-		fieldValue.Set(reflect.ValueOf(instance))
-	}
-
-	return nil
+	return w.fillStruct(baseValue.Elem(), newResolution(ctx, w))
 }
 
 // Resolve implements pkg.Container.
 func (w *wireContainer) Resolve(abstraction any) error {
+	return w.ResolveContext(context.Background(), abstraction)
+}
+
+// ResolveContext implements pkg.Container.
+func (w *wireContainer) ResolveContext(ctx context.Context, abstraction any) error {
+	if w.isClosed() {
+		return ErrClosed
+	}
 	abstractionVal := reflect.ValueOf(abstraction)
 	if abstractionVal.Kind() != reflect.Pointer {
 		return errors.NewError("abstranction must be a pointer to an interface")
@@ -127,54 +130,45 @@ func (w *wireContainer) Resolve(abstraction any) error {
 	}
 
 	abstractionType := abstractionVal.Elem().Type()
-	var instance any
-
-	spec, err := w.getSpec(abstractionType)
-	if err != nil {
-		return err
-	}
-	instance, err = spec.Resolve()
+	instance, err := w.resolveType(abstractionType, newResolution(ctx, w))
 	if err != nil {
 		return err
 	}
 
-	instanceType := reflect.TypeOf(instance)
-	if instanceType.Kind() == reflect.Interface {
-		if !instanceType.Implements(abstractionVal.Type().Elem()) {
-			return errors.NewError(fmt.Sprintf("worng resolver for type %v", abstractionType))
-		}
+	if !reflect.TypeOf(instance).AssignableTo(abstractionType) {
+		return errors.Errorf("wrong resolver for type %v", abstractionType).WithKind(errors.ErrWrongType)
 	}
 
 	abstractionVal.Elem().Set(reflect.ValueOf(instance))
 	return nil
 }
 
-// ResolveWithToken implements pkg.Container.
+// ResolveToken implements pkg.Container.
 func (w *wireContainer) ResolveToken(token string, abstraction any) error {
+	return w.ResolveTokenContext(context.Background(), token, abstraction)
+}
+
+// ResolveTokenContext implements pkg.Container.
+func (w *wireContainer) ResolveTokenContext(ctx context.Context, token string, abstraction any) error {
+	if w.isClosed() {
+		return ErrClosed
+	}
 	abstractionVal := reflect.ValueOf(abstraction)
 	if abstractionVal.Kind() != reflect.Pointer {
 		return errors.NewError("abstranction must be a pointer to an interface")
 	}
 	abstractionType := abstractionVal.Elem().Type()
-	var instance any
-
 	if !abstractionVal.Elem().CanSet() {
 		return errors.NewError("cannot set value of abstraction")
 	}
 
-	spec, err := w.getSpecForToken(token)
-	if err != nil {
-		return err
-	}
-	instance, err = spec.Resolve()
+	instance, err := w.resolveToken(token, newResolution(ctx, w))
 	if err != nil {
 		return err
 	}
 
-	if abstractionVal.Type().Elem().Kind() == reflect.Interface {
-		if !reflect.TypeOf(instance).Implements(abstractionVal.Type().Elem()) {
-			return errors.NewError(fmt.Sprintf("worng resolver for type %v", abstractionType))
-		}
+	if !reflect.TypeOf(instance).AssignableTo(abstractionType) {
+		return errors.Errorf("wrong resolver for type %v", abstractionType).WithKind(errors.ErrWrongType)
 	}
 
 	abstractionVal.Elem().Set(reflect.ValueOf(instance))
@@ -182,57 +176,118 @@ func (w *wireContainer) ResolveToken(token string, abstraction any) error {
 }
 
 // Singleton sets a resolver for the provided type with a singleton lifecycle. If a previous resolver was set
-// the new one overrides the previous one.
-func (w *wireContainer) Singleton(resolver any) error {
-	// May be here we can check if the resolver is valid
-	spec, err := newSpec(resolver, SINGLETON, w)
-	if err != nil {
-		return nil
+// the duplicate policy of the container is applied.
+func (w *wireContainer) Singleton(resolver any, options ...RegistrationOption) error {
+	return w.registerType(resolver, SINGLETON, options)
+}
+
+// Transient sets a resolver for the provided type with a transient lifecycle. If a previous resolver was set
+// the duplicate policy of the container is applied.
+func (w *wireContainer) Transient(resolver any, options ...RegistrationOption) error {
+	return w.registerType(resolver, TRANSIENT, options)
+}
+
+func (w *wireContainer) resolveToken(token string, res *resolution) (any, error) {
+	spec, exists := res.registry.tokenMapping[token]
+	if !exists {
+		return nil, res.fail(errors.ErrNotRegistered, tokenKey(token), "resolver for token '%s' not set", token)
 	}
-	spec.lifeCycle = SINGLETON
+	return spec.resolve(res, tokenKey(token))
+}
 
-	intfceType := spec.Type()
-	w.typeMapping[intfceType] = spec
-	return nil
+// resolveDependency resolves a dependency requested by a resolver or a struct field
+func (w *wireContainer) resolveDependency(dep dependency, res *resolution) (any, error) {
+	dep = res.registry.resolvedDependency(dep)
+	if dep.deferredType != nil {
+		return newDeferred(dep, res), nil
+	}
+	if dep.token != "" {
+		return w.resolveToken(dep.token, res)
+	}
+	if dep.refType == contextType {
+		return res.ctx, nil
+	}
+	return w.resolveType(dep.refType, res)
 }
 
-// Singleton sets a resolver for the provided type with a transient lifecycle. If a previous resolver was set
-// the new one overrides the previous one.
-func (w *wireContainer) Transient(resolver any) error {
-	// May be here we can check if the resolver is valid
-	spec, err := newSpec(resolver, TRANSIENT, w)
-	if err != nil {
-		return nil
+func (w *wireContainer) resolveType(reflectionType reflect.Type, res *resolution) (any, error) {
+	group, isGroup := res.registry.getGroup(reflectionType)
+	if isGroup {
+		return group.resolve(res, reflectionType)
 	}
-	spec.lifeCycle = TRANSIENT
 
-	intfceType := spec.Type()
-	w.typeMapping[intfceType] = spec
-	return nil
+	spec, exists := res.registry.typeMapping[reflectionType]
+	if !exists {
+		return nil, res.fail(errors.ErrNotRegistered, typeKey(reflectionType), "resolver for type '%s' not set", reflectionType.String())
+	}
+	return spec.resolve(res, typeKey(reflectionType))
 }
 
-func (w *wireContainer) getSpecForToken(token string) (*dependencySpec, error) {
-	spec, abstractionDefined := w.tokenMapping[token]
-	if !abstractionDefined {
-		message := fmt.Sprintf("resolver for token '%s' not set", token)
-		return &dependencySpec{}, errors.NewError(message)
+// Close implements pkg.Container.
+func (w *wireContainer) Close(ctx context.Context) error {
+	// The flag is set holding the mutex so instances created while closing are either closed
+	// here or rejected by instantiated
+	w.instancesMutex.Lock()
+	if w.closed.Swap(true) {
+		w.instancesMutex.Unlock()
+		return nil
 	}
-	return spec, nil
+	instances := w.instances
+	w.instances = nil
+	w.instancesMutex.Unlock()
+
+	var problems []error
+	closed := make(map[any]bool)
+	for i := len(instances) - 1; i >= 0; i-- {
+		instance := instances[i].instance
+		if reflect.TypeOf(instance).Comparable() {
+			if closed[instance] {
+				continue
+			}
+			closed[instance] = true
+		}
+
+		err := closeInstance(ctx, instance)
+		if err != nil {
+			problems = append(problems, errors.Errorf("error closing %s: %w", instances[i].key, err))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.WrapError(stderrors.Join(problems...))
 }
 
-func (w *wireContainer) getSpec(reflectType reflect.Type) (*dependencySpec, error) {
-	spec, abstractionDefined := w.typeMapping[reflectType]
-	if !abstractionDefined {
-		message := fmt.Sprintf("resolver for type '%s' not set", reflectType.String())
-		return &dependencySpec{}, errors.NewError(message)
+// instantiated registers an instance cached by the container so it can be closed later. The
+// instance replaces the previous instance of the spec keeping its position. It returns false
+// if the container has been closed, the instance must not be cached then.
+func (w *wireContainer) instantiated(spec *dependencySpec, key string, instance any) bool {
+	w.instancesMutex.Lock()
+	defer w.instancesMutex.Unlock()
+	if w.isClosed() {
+		return false
+	}
+	if spec.unmanaged {
+		return true
 	}
-	return spec, nil
+	for i := range w.instances {
+		if w.instances[i].spec == spec {
+			w.instances[i].instance = instance
+			return true
+		}
+	}
+	w.instances = append(w.instances, cachedInstance{spec: spec, key: key, instance: instance})
+	return true
 }
 
-func (w *wireContainer) resolveType(reflectionType reflect.Type) (any, error) {
-	spec, exists := w.typeMapping[reflectionType]
-	if !exists {
-		return nil, errors.Errorf("resolver not defined for %s", reflectionType.String())
+func closeInstance(ctx context.Context, instance any) error {
+	switch closer := instance.(type) {
+	case Shutdowner:
+		return closer.Shutdown(ctx)
+	case io.Closer:
+		return closer.Close()
+	default:
+		return nil
 	}
-	return spec.Resolve()
 }
@@ -1,10 +1,13 @@
 package pkg
 
 import (
+	"context"
+	"errors"
 	"io"
 	"reflect"
 	"testing"
 
+	wiringErrors "github.com/4strodev/wiring/pkg/errors"
 	"github.com/4strodev/wiring/pkg/internal/mocks"
 	"github.com/stretchr/testify/require"
 )
@@ -135,4 +138,295 @@ func TestFill(t *testing.T) {
 		err = container.Fill(&fillableStruct)
 		require.Error(t, err)
 	})
+	t.Run("should return the error of a failing type based field", func(t *testing.T) {
+		var err error
+		container := New()
+		err = container.SingletonToken(mocks.TESTING_TOKEN, mocks.TokenResolver)
+		require.NoError(t, err)
+		err = container.Transient(func() (mocks.Abstraction, error) {
+			return nil, io.ErrUnexpectedEOF
+		})
+		require.NoError(t, err)
+
+		var fillableStruct mocks.FillableStruct
+		err = container.Fill(&fillableStruct)
+		require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+		require.Nil(t, fillableStruct.TypeResolved)
+	})
+}
+
+type cyclicDB struct{}
+type cyclicRepo struct{}
+
+func TestCircularDependency(t *testing.T) {
+	newCyclicContainer := func(t *testing.T, lifeCycle func(Container, any, ...RegistrationOption) error) Container {
+		container := New()
+		err := lifeCycle(container, func(*cyclicRepo) *cyclicDB {
+			return &cyclicDB{}
+		})
+		require.NoError(t, err)
+		err = lifeCycle(container, func(*cyclicDB) *cyclicRepo {
+			return &cyclicRepo{}
+		})
+		require.NoError(t, err)
+		return container
+	}
+
+	t.Run("should detect cycles between singletons", func(t *testing.T) {
+		container := newCyclicContainer(t, Container.Singleton)
+		var db *cyclicDB
+		err := container.Resolve(&db)
+		require.EqualError(t, err, "circular dependency detected: *pkg.cyclicDB -> *pkg.cyclicRepo -> *pkg.cyclicDB")
+		require.Nil(t, db)
+	})
+	t.Run("should detect cycles between transients", func(t *testing.T) {
+		container := newCyclicContainer(t, Container.Transient)
+		var repo *cyclicRepo
+		err := container.Resolve(&repo)
+		require.EqualError(t, err, "circular dependency detected: *pkg.cyclicRepo -> *pkg.cyclicDB -> *pkg.cyclicRepo")
+	})
+	t.Run("should detect cycles when filling structs", func(t *testing.T) {
+		container := newCyclicContainer(t, Container.Singleton)
+		var fillable struct {
+			DB *cyclicDB
+		}
+		err := container.Fill(&fillable)
+		require.ErrorContains(t, err, "circular dependency detected")
+	})
+	t.Run("should detect cycles resolving by token", func(t *testing.T) {
+		container := newCyclicContainer(t, Container.Singleton)
+		err := container.SingletonToken("db", func(db *cyclicDB) *cyclicDB {
+			return db
+		})
+		require.NoError(t, err)
+		var db *cyclicDB
+		err = container.ResolveToken("db", &db)
+		require.EqualError(t, err, "circular dependency detected: *pkg.cyclicDB -> *pkg.cyclicRepo -> *pkg.cyclicDB")
+	})
+	t.Run("should allow resolving the same dependency twice in a chain", func(t *testing.T) {
+		container := InitializeContainer(t)
+		err := container.Singleton(func(a mocks.Abstraction, b mocks.Abstraction) ComplexAbstraction {
+			return ComplexImplementation{Abstraction: a}
+		})
+		require.NoError(t, err)
+		var complexAbstraction ComplexAbstraction
+		require.NoError(t, container.Resolve(&complexAbstraction))
+	})
+}
+
+type closableResource struct {
+	name   string
+	closed *[]string
+	err    error
+}
+
+func (r *closableResource) Close() error {
+	*r.closed = append(*r.closed, r.name)
+	return r.err
+}
+
+type shutdownResource struct {
+	closableResource
+}
+
+func (r *shutdownResource) Shutdown(ctx context.Context) error {
+	*r.closed = append(*r.closed, "shutdown "+r.name)
+	return r.err
+}
+
+func TestClose(t *testing.T) {
+	t.Run("should close singletons in reverse creation order", func(t *testing.T) {
+		var closed []string
+		container := New()
+		err := container.Singleton(func() *closableResource {
+			return &closableResource{name: "first", closed: &closed}
+		})
+		require.NoError(t, err)
+		err = container.Singleton(func(*closableResource) *shutdownResource {
+			return &shutdownResource{closableResource{name: "second", closed: &closed}}
+		})
+		require.NoError(t, err)
+		err = container.Transient(func() io.Closer {
+			return &closableResource{name: "transient", closed: &closed}
+		})
+		require.NoError(t, err)
+
+		var second *shutdownResource
+		require.NoError(t, container.Resolve(&second))
+		var transient io.Closer
+		require.NoError(t, container.Resolve(&transient))
+
+		require.NoError(t, container.Close(context.Background()))
+		require.Equal(t, []string{"shutdown second", "first"}, closed)
+	})
+	t.Run("should aggregate close errors", func(t *testing.T) {
+		var closed []string
+		container := New()
+		err := container.Singleton(func() *closableResource {
+			return &closableResource{name: "first", closed: &closed, err: errors.New("first failed")}
+		})
+		require.NoError(t, err)
+		err = container.SingletonToken("second", func() *closableResource {
+			return &closableResource{name: "second", closed: &closed, err: errors.New("second failed")}
+		})
+		require.NoError(t, err)
+
+		var first, second *closableResource
+		require.NoError(t, container.Resolve(&first))
+		require.NoError(t, container.ResolveToken("second", &second))
+
+		err = container.Close(context.Background())
+		require.EqualError(t, err, "error closing 'second': second failed\nerror closing *pkg.closableResource: first failed")
+		require.Equal(t, []string{"second", "first"}, closed)
+	})
+	t.Run("should refuse resolving once closed", func(t *testing.T) {
+		container := InitializeContainer(t)
+		require.NoError(t, container.Close(context.Background()))
+		require.NoError(t, container.Close(context.Background()))
+
+		var abstraction mocks.Abstraction
+		require.ErrorIs(t, container.Resolve(&abstraction), ErrClosed)
+		var value string
+		require.ErrorIs(t, container.ResolveToken(mocks.TESTING_TOKEN, &value), ErrClosed)
+		var fillableStruct mocks.FillableStruct
+		require.ErrorIs(t, container.Fill(&fillableStruct), ErrClosed)
+	})
+}
+
+type contextKey struct{}
+
+func TestResolveContext(t *testing.T) {
+	t.Run("should inject the caller context into resolvers", func(t *testing.T) {
+		container := New()
+		err := container.Transient(func(ctx context.Context) mocks.Abstraction {
+			return &mocks.Implementation{Message: ctx.Value(contextKey{}).(string)}
+		})
+		require.NoError(t, err)
+		err = container.TransientToken(mocks.TESTING_TOKEN, func(ctx context.Context, abstraction mocks.Abstraction) string {
+			return abstraction.(*mocks.Implementation).Message
+		})
+		require.NoError(t, err)
+
+		ctx := context.WithValue(context.Background(), contextKey{}, "from context")
+		var abstraction mocks.Abstraction
+		require.NoError(t, container.ResolveContext(ctx, &abstraction))
+		require.Equal(t, "from context", abstraction.(*mocks.Implementation).Message)
+
+		var message string
+		require.NoError(t, container.ResolveTokenContext(ctx, mocks.TESTING_TOKEN, &message))
+		require.Equal(t, "from context", message)
+
+		var fillableStruct mocks.FillableStruct
+		require.NoError(t, container.FillContext(ctx, &fillableStruct))
+		require.Equal(t, "from context", fillableStruct.TokenResolved)
+		require.NoError(t, container.Validate())
+	})
+	t.Run("should inject the background context when no context is provided", func(t *testing.T) {
+		container := New()
+		err := container.Transient(func(ctx context.Context) mocks.Abstraction {
+			require.NotNil(t, ctx)
+			return &mocks.Implementation{}
+		})
+		require.NoError(t, err)
+		var abstraction mocks.Abstraction
+		require.NoError(t, container.Resolve(&abstraction))
+	})
+	t.Run("should abort the resolution chain when the context is cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		container := New()
+		err := container.Singleton(func() mocks.Abstraction {
+			cancel()
+			return &mocks.Implementation{}
+		})
+		require.NoError(t, err)
+		err = container.Singleton(func() io.Reader {
+			t.Fatal("resolver executed after cancellation")
+			return nil
+		})
+		require.NoError(t, err)
+		err = container.Singleton(func(mocks.Abstraction, io.Reader) ComplexAbstraction {
+			return ComplexImplementation{}
+		})
+		require.NoError(t, err)
+
+		var complexAbstraction ComplexAbstraction
+		err = container.ResolveContext(ctx, &complexAbstraction)
+		require.ErrorIs(t, err, context.Canceled)
+		require.EqualError(t, err, "resolution of io.Reader aborted: context canceled")
+	})
+}
+
+func TestErrorKinds(t *testing.T) {
+	t.Run("should report missing dependencies with their path", func(t *testing.T) {
+		container := New()
+		err := container.Singleton(func(abstraction mocks.Abstraction) ComplexAbstraction {
+			return ComplexImplementation{Abstraction: abstraction}
+		})
+		require.NoError(t, err)
+
+		var complexAbstraction ComplexAbstraction
+		err = container.Resolve(&complexAbstraction)
+		require.ErrorIs(t, err, wiringErrors.ErrNotRegistered)
+		var wiringError *wiringErrors.WiringError
+		require.ErrorAs(t, err, &wiringError)
+		require.Equal(t, "mocks.Abstraction", wiringError.Key)
+		require.Equal(t, []string{"pkg.ComplexAbstraction", "mocks.Abstraction"}, wiringError.Path)
+	})
+	t.Run("should keep resolver errors", func(t *testing.T) {
+		cause := errors.New("connection refused")
+		container := New()
+		err := container.Singleton(func() (mocks.Abstraction, error) {
+			return nil, cause
+		})
+		require.NoError(t, err)
+		err = container.SingletonToken(mocks.TESTING_TOKEN, func(abstraction mocks.Abstraction) string {
+			return ""
+		})
+		require.NoError(t, err)
+
+		var value string
+		err = container.ResolveToken(mocks.TESTING_TOKEN, &value)
+		require.ErrorIs(t, err, cause)
+		require.ErrorIs(t, err, wiringErrors.ErrResolverFailed)
+		require.EqualError(t, err, "resolver of mocks.Abstraction failed: connection refused")
+		var wiringError *wiringErrors.WiringError
+		require.ErrorAs(t, err, &wiringError)
+		require.Equal(t, []string{"'token'", "mocks.Abstraction"}, wiringError.Path)
+	})
+	t.Run("should classify nil instances, wrong types and cycles", func(t *testing.T) {
+		container := New()
+		err := container.Singleton(func() mocks.Abstraction {
+			return nil
+		})
+		require.NoError(t, err)
+		err = container.SingletonToken(mocks.TESTING_TOKEN, mocks.TokenResolver)
+		require.NoError(t, err)
+		err = container.Singleton(func(*cyclicRepo) *cyclicDB {
+			return &cyclicDB{}
+		})
+		require.NoError(t, err)
+		err = container.Singleton(func(*cyclicDB) *cyclicRepo {
+			return &cyclicRepo{}
+		})
+		require.NoError(t, err)
+
+		var abstraction mocks.Abstraction
+		require.ErrorIs(t, container.Resolve(&abstraction), wiringErrors.ErrNilInstance)
+		var number int
+		require.ErrorIs(t, container.ResolveToken(mocks.TESTING_TOKEN, &number), wiringErrors.ErrWrongType)
+		var db *cyclicDB
+		require.ErrorIs(t, container.Resolve(&db), wiringErrors.ErrCircularDependency)
+		require.ErrorIs(t, container.Singleton("not a function"), wiringErrors.ErrInvalidResolver)
+	})
+	t.Run("should classify nil transient instances", func(t *testing.T) {
+		container := New()
+		require.NoError(t, container.Transient(func() io.Reader { return nil }))
+		require.NoError(t, container.TransientToken("reader", func() io.Reader { return nil }))
+
+		var reader io.Reader
+		require.ErrorIs(t, container.Resolve(&reader), wiringErrors.ErrNilInstance)
+		require.ErrorIs(t, container.ResolveToken("reader", &reader), wiringErrors.ErrNilInstance)
+		var filled struct{ Reader io.Reader }
+		require.ErrorIs(t, container.Fill(&filled), wiringErrors.ErrNilInstance)
+	})
 }
@@ -0,0 +1,157 @@
+package pkg
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/4strodev/wiring/pkg/errors"
+)
+
+// decorator wraps the instances of a type or token. It is a function like
+// func(T, dependencies...) (T, error) whose dependencies are resolved like the ones of a resolver.
+type decorator struct {
+	resolver      reflect.Value
+	decoratedType reflect.Type
+	// token is set for decorators of token based dependencies
+	token  string
+	source string
+}
+
+// decoration is a cached instance once the decorators of a key have been applied to it
+type decoration struct {
+	instance any
+	// applied is the number of decorators applied, decorators registered later are applied on top
+	applied int
+}
+
+func newDecorator(resolver any) (*decorator, error) {
+	resolverType := reflect.TypeOf(resolver)
+	if resolverType == nil || resolverType.Kind() != reflect.Func {
+		return nil, errors.NewError("decorator not valid it should be a function").WithKind(errors.ErrInvalidResolver)
+	}
+	if resolverType.NumIn() < 1 || resolverType.NumOut() < 1 || resolverType.NumOut() > 2 || resolverType.In(0) != resolverType.Out(0) {
+		return nil, errors.Errorf("decorator should be like func(T, dependencies...) (T, error), got %s", resolverType).WithKind(errors.ErrInvalidResolver)
+	}
+	if resolverType.NumOut() == 2 && resolverType.Out(1) != reflect.TypeFor[error]() {
+		return nil, errors.NewError("second return type of decorator is not an error").WithKind(errors.ErrInvalidResolver)
+	}
+
+	return &decorator{
+		resolver:      reflect.ValueOf(resolver),
+		decoratedType: resolverType.In(0),
+		source:        registrationSource(),
+	}, nil
+}
+
+// dependencies returns the dependencies of the decorator besides the decorated instance
+func (d *decorator) dependencies(r *registry) []dependency {
+	resolverType := d.resolver.Type()
+	dependencies := make([]dependency, 0, resolverType.NumIn()-1)
+	for i := 1; i < resolverType.NumIn(); i++ {
+		dependencies = append(dependencies, argumentDependencies(r, resolverType.In(i))...)
+	}
+	return dependencies
+}
+
+// apply calls the decorator with the instance resolving the rest of its arguments
+func (d *decorator) apply(res *resolution, key string, instance any) (any, error) {
+	instanceValue := reflect.ValueOf(instance)
+	if !instanceValue.IsValid() || !instanceValue.Type().AssignableTo(d.decoratedType) {
+		return nil, res.fail(errors.ErrWrongType, key, "decorator of %s expects %s, got %T", key, d.decoratedType, instance)
+	}
+
+	resolverType := d.resolver.Type()
+	values := make([]reflect.Value, resolverType.NumIn())
+	values[0] = instanceValue
+	for i := 1; i < resolverType.NumIn(); i++ {
+		value, err := resolveArgument(resolverType.In(i), res)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+
+	returnedValues := d.resolver.Call(values)
+	if len(returnedValues) == 2 && !returnedValues[1].IsNil() {
+		err := returnedValues[1].Interface().(error)
+		return nil, res.fail(errors.ErrResolverFailed, key, "decorator of %s failed: %w", key, err)
+	}
+	decorated := returnedValues[0].Interface()
+	if decorated == nil {
+		return nil, res.fail(errors.ErrNilInstance, key, "decorator of %s returned a nil instance", key)
+	}
+	return decorated, nil
+}
+
+// decorate applies the decorators of the key in registration order. When a cache is provided
+// the decorated instance is stored in it so decorators are applied just once per instance.
+func decorate(res *resolution, key string, instance any, cache *map[string]decoration) (any, error) {
+	decorators := res.registry.decorators[key]
+	if len(decorators) == 0 {
+		return instance, nil
+	}
+
+	applied := 0
+	if cache != nil {
+		if cached, exists := (*cache)[key]; exists {
+			instance, applied = cached.instance, cached.applied
+		}
+	}
+	for _, d := range decorators[applied:] {
+		var err error
+		instance, err = d.apply(res, key, instance)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cache != nil && applied < len(decorators) {
+		if *cache == nil {
+			*cache = make(map[string]decoration)
+		}
+		(*cache)[key] = decoration{instance: instance, applied: len(decorators)}
+	}
+	return instance, nil
+}
+
+// decoratorSignatures returns the signatures of the decorators of the key
+func (r *registry) decoratorSignatures(key string) []string {
+	var signatures []string
+	for _, d := range r.decorators[key] {
+		signatures = append(signatures, d.resolver.Type().String())
+	}
+	return signatures
+}
+
+func (w *wireContainer) addDecorator(key string, d *decorator) error {
+	return w.updateRegistry(func(r *registry) error {
+		// Slices are shared with previous snapshots so appending must never reuse their array
+		r.decorators[key] = append(slices.Clip(r.decorators[key]), d)
+		return nil
+	})
+}
+
+// Decorate implements pkg.Container.
+func (w *wireContainer) Decorate(decorator any) error {
+	d, err := newDecorator(decorator)
+	if err != nil {
+		return err
+	}
+	return w.addDecorator(typeKey(d.decoratedType), d)
+}
+
+// DecorateToken implements pkg.Container.
+func (w *wireContainer) DecorateToken(token string, decorator any) error {
+	d, err := newDecorator(decorator)
+	if err != nil {
+		return err
+	}
+	d.token = token
+	return w.addDecorator(tokenKey(token), d)
+}
+
+// String describes the decorator for error messages
+func (d *decorator) String() string {
+	return fmt.Sprintf("decorator %s registered at %s", d.resolver.Type(), d.source)
+}
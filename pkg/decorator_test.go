@@ -0,0 +1,171 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	wiringErrors "github.com/4strodev/wiring/pkg/errors"
+	"github.com/4strodev/wiring/pkg/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// prefixed decorates a greeter prefixing its message
+type prefixed struct {
+	mocks.Abstraction
+	prefix string
+}
+
+func (p *prefixed) message() string {
+	if inner, ok := p.Abstraction.(*prefixed); ok {
+		return p.prefix + inner.message()
+	}
+	return p.prefix + p.Abstraction.(*mocks.Implementation).Message
+}
+
+type messageParams struct {
+	In
+	Message string `wire:"token"`
+}
+
+func prefix(value string) func(mocks.Abstraction) mocks.Abstraction {
+	return func(abstraction mocks.Abstraction) mocks.Abstraction {
+		return &prefixed{Abstraction: abstraction, prefix: value}
+	}
+}
+
+func TestDecorate(t *testing.T) {
+	t.Run("should apply decorators in registration order", func(t *testing.T) {
+		container := InitializeContainer(t)
+		require.NoError(t, container.Decorate(prefix("a ")))
+		require.NoError(t, container.Decorate(func(abstraction mocks.Abstraction, params messageParams) (mocks.Abstraction, error) {
+			return &prefixed{Abstraction: abstraction, prefix: params.Message + " "}, nil
+		}))
+
+		abstraction, err := Get[mocks.Abstraction](container)
+		require.NoError(t, err)
+		require.Equal(t, mocks.DEFAULT_MESSAGE+" a "+mocks.DEFAULT_MESSAGE, abstraction.(*prefixed).message())
+	})
+	t.Run("should cache decorated singletons", func(t *testing.T) {
+		container := InitializeContainer(t)
+		calls := 0
+		require.NoError(t, container.Decorate(func(abstraction mocks.Abstraction) mocks.Abstraction {
+			calls++
+			return &prefixed{Abstraction: abstraction, prefix: "a "}
+		}))
+
+		first, err := Get[mocks.Abstraction](container)
+		require.NoError(t, err)
+		second, err := Get[mocks.Abstraction](container)
+		require.NoError(t, err)
+		require.Same(t, first, second)
+		require.Equal(t, 1, calls)
+
+		require.NoError(t, container.Decorate(prefix("b ")))
+		third, err := Get[mocks.Abstraction](container)
+		require.NoError(t, err)
+		require.Same(t, first, third.(*prefixed).Abstraction)
+		require.Equal(t, 1, calls)
+	})
+	t.Run("should decorate transients every time", func(t *testing.T) {
+		container := New()
+		require.NoError(t, container.Transient(mocks.Resolver))
+		calls := 0
+		require.NoError(t, container.Decorate(func(abstraction mocks.Abstraction) mocks.Abstraction {
+			calls++
+			return abstraction
+		}))
+
+		_, err := Get[mocks.Abstraction](container)
+		require.NoError(t, err)
+		_, err = Get[mocks.Abstraction](container)
+		require.NoError(t, err)
+		require.Equal(t, 2, calls)
+	})
+	t.Run("should decorate scoped instances once per scope", func(t *testing.T) {
+		container := New()
+		require.NoError(t, container.Scoped(mocks.Resolver))
+		calls := 0
+		require.NoError(t, container.Decorate(func(abstraction mocks.Abstraction) mocks.Abstraction {
+			calls++
+			return &prefixed{Abstraction: abstraction}
+		}))
+
+		scope := container.NewScope()
+		first, err := Get[mocks.Abstraction](scope)
+		require.NoError(t, err)
+		second, err := Get[mocks.Abstraction](scope)
+		require.NoError(t, err)
+		require.Same(t, first, second)
+		_, err = Get[mocks.Abstraction](container.NewScope())
+		require.NoError(t, err)
+		require.Equal(t, 2, calls)
+	})
+	t.Run("should decorate tokens", func(t *testing.T) {
+		container := InitializeContainer(t)
+		require.NoError(t, container.DecorateToken(mocks.TESTING_TOKEN, func(message string, ctx context.Context) string {
+			require.NotNil(t, ctx)
+			return "decorated " + message
+		}))
+
+		message, err := GetToken[string](container, mocks.TESTING_TOKEN)
+		require.NoError(t, err)
+		require.Equal(t, "decorated "+mocks.DEFAULT_MESSAGE, message)
+	})
+	t.Run("should only decorate the key it is registered for", func(t *testing.T) {
+		container := New()
+		err := container.Singleton(func() *store {
+			return &store{value: "stored"}
+		}, As(reflect.TypeFor[reader]()))
+		require.NoError(t, err)
+		require.NoError(t, container.Decorate(func(r reader) reader {
+			return &store{value: "decorated " + r.Read()}
+		}))
+
+		r, err := Get[reader](container)
+		require.NoError(t, err)
+		require.Equal(t, "decorated stored", r.Read())
+		s, err := Get[*store](container)
+		require.NoError(t, err)
+		require.Equal(t, "stored", s.Read())
+	})
+	t.Run("should return the error of the decorator", func(t *testing.T) {
+		container := InitializeContainer(t)
+		decoratorErr := errors.New("decorator failed")
+		require.NoError(t, container.Decorate(func(abstraction mocks.Abstraction) (mocks.Abstraction, error) {
+			return nil, decoratorErr
+		}))
+
+		_, err := Get[mocks.Abstraction](container)
+		require.ErrorIs(t, err, decoratorErr)
+		require.ErrorIs(t, err, wiringErrors.ErrResolverFailed)
+		require.EqualError(t, err, "decorator of mocks.Abstraction failed: decorator failed")
+	})
+	t.Run("should reject invalid decorators", func(t *testing.T) {
+		container := InitializeContainer(t)
+		require.ErrorIs(t, container.Decorate(mocks.Resolver), wiringErrors.ErrInvalidResolver)
+		require.ErrorIs(t, container.Decorate("decorator"), wiringErrors.ErrInvalidResolver)
+		require.ErrorIs(t, container.Decorate(func(mocks.Abstraction) string { return "" }), wiringErrors.ErrInvalidResolver)
+		require.ErrorIs(t, container.DecorateToken(mocks.TESTING_TOKEN, func(string) (string, string) { return "", "" }), wiringErrors.ErrInvalidResolver)
+	})
+	t.Run("should validate and describe decorators", func(t *testing.T) {
+		container := New()
+		require.NoError(t, container.Decorate(prefix("a ")))
+		require.NoError(t, container.DecorateToken(mocks.TESTING_TOKEN, func(message string, reader reader) string {
+			return message
+		}))
+		err := container.Validate()
+		require.ErrorIs(t, err, wiringErrors.ErrNotRegistered)
+		require.ErrorContains(t, err, "decorates 'token' which is not set")
+		require.ErrorContains(t, err, "decorates mocks.Abstraction which is not set")
+
+		require.NoError(t, container.Singleton(mocks.Resolver))
+		require.NoError(t, container.SingletonToken(mocks.TESTING_TOKEN, mocks.TokenResolver))
+		require.EqualError(t, container.Validate(), "resolver for 'token' requires type 'pkg.reader' which is not set")
+
+		registrations := container.Registrations()
+		require.Equal(t, []string{"func(mocks.Abstraction) mocks.Abstraction"}, registrations[0].Decorators)
+		require.Equal(t, []string{"func(string, pkg.reader) string"}, registrations[1].Decorators)
+	})
+}
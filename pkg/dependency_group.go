@@ -0,0 +1,94 @@
+package pkg
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/4strodev/wiring/pkg/errors"
+)
+
+// dependencyGroup holds several specs resolving the same type. Resolving the group
+// returns a slice with the instances of its members in registration order.
+type dependencyGroup struct {
+	members []groupMember
+}
+
+type groupMember struct {
+	name string
+	spec *dependencySpec
+}
+
+// member returns the spec of the member with the provided name, nil if the member does not exist
+func (group *dependencyGroup) member(name string) *dependencySpec {
+	for _, member := range group.members {
+		if member.name == name {
+			return member.spec
+		}
+	}
+	return nil
+}
+
+// add adds the spec to the group. If a member with the same name exists it is replaced keeping its position.
+func (group *dependencyGroup) add(name string, spec *dependencySpec) {
+	for i, member := range group.members {
+		if member.name == name {
+			group.members[i].spec = spec
+			return
+		}
+	}
+	group.members = append(group.members, groupMember{name: name, spec: spec})
+}
+
+// resolve resolves every member of the group returning a slice of sliceType
+func (group *dependencyGroup) resolve(res *resolution, sliceType reflect.Type) (any, error) {
+	instances := reflect.MakeSlice(sliceType, 0, len(group.members))
+	for _, member := range group.members {
+		instance, err := member.spec.resolve(res, groupKey(sliceType.Elem(), member.name))
+		if err != nil {
+			return nil, err
+		}
+		if instance == nil {
+			key := groupKey(sliceType.Elem(), member.name)
+			return nil, res.fail(errors.ErrNilInstance, key, "resolver of group member %s returned a nil instance", key)
+		}
+		instances = reflect.Append(instances, reflect.ValueOf(instance))
+	}
+	return instances.Interface(), nil
+}
+
+func groupKey(refType reflect.Type, name string) string {
+	return fmt.Sprintf("%s[%s]", typeKey(refType), name)
+}
+
+// SingletonGroup implements pkg.Container.
+func (w *wireContainer) SingletonGroup(name string, resolver any) error {
+	return w.addGroupMember(name, resolver, SINGLETON)
+}
+
+// TransientGroup implements pkg.Container.
+func (w *wireContainer) TransientGroup(name string, resolver any) error {
+	return w.addGroupMember(name, resolver, TRANSIENT)
+}
+
+func (w *wireContainer) addGroupMember(name string, resolver any, lifeCycle abstractionLifeCycle) error {
+	spec, err := newSpec(resolver, lifeCycle, w)
+	if err != nil {
+		return err
+	}
+
+	return w.updateRegistry(func(r *registry) error {
+		group := new(dependencyGroup)
+		previous, exists := r.groupMapping[spec.Type()]
+		if exists {
+			group.members = slices.Clone(previous.members)
+		}
+		err := w.checkDuplicate(groupKey(spec.Type(), name), group.member(name))
+		if err != nil {
+			return err
+		}
+		group.add(name, spec)
+		r.groupMapping[spec.Type()] = group
+		return nil
+	})
+}
@@ -0,0 +1,108 @@
+package pkg
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/4strodev/wiring/pkg/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+type healthChecker interface {
+	Name() string
+}
+
+type namedChecker string
+
+func (c namedChecker) Name() string {
+	return string(c)
+}
+
+func checkerNames(checkers []healthChecker) []string {
+	names := make([]string, len(checkers))
+	for i, checker := range checkers {
+		names[i] = checker.Name()
+	}
+	return names
+}
+
+func newGroupContainer(t *testing.T) Container {
+	container := New()
+	for _, name := range []string{"db", "cache", "queue"} {
+		checker := namedChecker(name)
+		err := container.SingletonGroup(name, func() healthChecker {
+			return checker
+		})
+		require.NoError(t, err)
+	}
+	return container
+}
+
+func TestGroups(t *testing.T) {
+	t.Run("should resolve groups in registration order", func(t *testing.T) {
+		container := newGroupContainer(t)
+		var checkers []healthChecker
+		require.NoError(t, container.Resolve(&checkers))
+		require.Equal(t, []string{"db", "cache", "queue"}, checkerNames(checkers))
+		require.True(t, container.HasType(reflect.TypeFor[[]healthChecker]()))
+		require.False(t, container.HasType(reflect.TypeFor[healthChecker]()))
+	})
+	t.Run("should inject groups as resolver parameters", func(t *testing.T) {
+		container := newGroupContainer(t)
+		err := container.TransientGroup("disk", func(ctx context.Context) healthChecker {
+			return namedChecker("disk")
+		})
+		require.NoError(t, err)
+		err = container.Singleton(func(checkers []healthChecker) []string {
+			return checkerNames(checkers)
+		})
+		require.NoError(t, err)
+
+		var names []string
+		require.NoError(t, container.Resolve(&names))
+		require.Equal(t, []string{"db", "cache", "queue", "disk"}, names)
+		require.NoError(t, container.Validate())
+	})
+	t.Run("should replace members with the same name keeping their position", func(t *testing.T) {
+		container := newGroupContainer(t)
+		err := container.SingletonGroup("cache", func() healthChecker {
+			return namedChecker("redis")
+		})
+		require.NoError(t, err)
+
+		var checkers []healthChecker
+		require.NoError(t, container.Resolve(&checkers))
+		require.Equal(t, []string{"db", "redis", "queue"}, checkerNames(checkers))
+	})
+	t.Run("should prefer slices registered with their own resolver", func(t *testing.T) {
+		container := newGroupContainer(t)
+		err := container.Singleton(func() []healthChecker {
+			return []healthChecker{namedChecker("custom")}
+		})
+		require.NoError(t, err)
+
+		var checkers []healthChecker
+		require.NoError(t, container.Resolve(&checkers))
+		require.Equal(t, []string{"custom"}, checkerNames(checkers))
+	})
+	t.Run("should report missing dependencies of members", func(t *testing.T) {
+		container := newGroupContainer(t)
+		err := container.SingletonGroup("greeter", func(abstraction mocks.Abstraction) healthChecker {
+			return namedChecker("greeter")
+		})
+		require.NoError(t, err)
+
+		var checkers []healthChecker
+		require.Error(t, container.Resolve(&checkers))
+		require.EqualError(t, container.Validate(), "resolver for pkg.healthChecker[greeter] requires type 'mocks.Abstraction' which is not set")
+	})
+	t.Run("should describe group members", func(t *testing.T) {
+		container := newGroupContainer(t)
+		registrations := container.Registrations()
+		require.Len(t, registrations, 3)
+		require.Equal(t, "pkg.healthChecker[db]", registrations[0].Key)
+		require.Equal(t, "db", registrations[0].Group)
+		require.Equal(t, reflect.TypeFor[healthChecker](), registrations[0].Type)
+	})
+}
@@ -1,118 +1,298 @@
 package pkg
 
 import (
+	"context"
 	"reflect"
 	"sync"
+	"sync/atomic"
 
 	"github.com/4strodev/wiring/pkg/errors"
 )
 
 type abstractionLifeCycle uint8
 
+var contextType = reflect.TypeFor[context.Context]()
+
 const (
 	SINGLETON abstractionLifeCycle = iota
 	TRANSIENT
+	SCOPED
 )
 
 // dependencySpec defines how the abstraction is resolved
 type dependencySpec struct {
-	container  *wireContainer
-	lifeCycle  abstractionLifeCycle
-	resolver   any
+	container *wireContainer
+	lifeCycle abstractionLifeCycle
+	resolver  any
+	// structType is set when the spec fills a struct instead of calling a resolver
+	structType reflect.Type
 	instance   any
 	returnType reflect.Type
 	mutex      sync.Mutex
+	// instantiated reports if the singleton instance has been cached
+	instantiated atomic.Bool
+	// source is the file:line where the spec was registered
+	source string
+	// plan is set when the container is built
+	plan atomic.Pointer[resolverPlan]
+	// decorations are the singleton instance decorated for every key it was resolved with
+	decorations map[string]decoration
+	// eager singletons are instantiated by WarmUp
+	eager bool
+	// unmanaged instances are never closed by the container
+	unmanaged bool
+	retry     *RetryPolicy
+	// failure is the last error of the resolver memoized by the retry policy
+	failure atomic.Pointer[failure]
+
+	refresh       *RefreshPolicy
+	followRefresh bool
+	// version is increased every time a singleton instance is cached
+	version atomic.Uint64
+	// expires is the time in unix nanoseconds when the singleton instance expires, zero if it never does
+	expires     atomic.Int64
+	invalidated atomic.Bool
+	refreshing  atomic.Bool
+	// dependencyVersions are the versions of the singleton dependencies the instance was built with
+	dependencyVersions atomic.Pointer[map[*dependencySpec]uint64]
 }
 
 func (spec *dependencySpec) Type() reflect.Type {
 	return spec.returnType
 }
 
+// Resolve resolves the spec starting a new resolution chain
 func (spec *dependencySpec) Resolve() (any, error) {
+	return spec.resolve(newResolution(context.Background(), spec.container), typeKey(spec.returnType))
+}
+
+// resolve resolves the spec as part of the provided resolution chain. The key is
+// the name used to identify the spec on the chain.
+func (spec *dependencySpec) resolve(res *resolution, key string) (any, error) {
+	instance, _, err := spec.trace(res, key)
+	return instance, err
+}
+
+// resolveStep adds the spec to the chain and resolves it. It reports if the instance was cached.
+func (spec *dependencySpec) resolveStep(res *resolution, key string) (instance any, cached bool, err error) {
+	err = res.enter(key, spec)
+	if err != nil {
+		return nil, false, err
+	}
+	defer res.leave()
+
+	instance, err = spec.resolveInstance(res, key)
+	return instance, !res.steps[len(res.steps)-1].created, err
+}
+
+// resolveInstance returns the instance of the spec following its lifecycle
+func (spec *dependencySpec) resolveInstance(res *resolution, key string) (any, error) {
 	switch spec.lifeCycle {
 	case SINGLETON:
 		spec.mutex.Lock()
 		defer spec.mutex.Unlock()
-		if spec.instance == nil {
-			instance, err := spec.executeResolver()
+		// Singleton dependencies are always resolved from the root container so they
+		// never capture instances of a scope
+		scope := res.container
+		res.container = spec.container
+		defer func() {
+			res.container = scope
+		}()
+		rebuild := spec.instance == nil
+		if !rebuild && spec.stale() {
+			if spec.refresh != nil && spec.refresh.Background {
+				spec.refreshInBackground(res.ctx, key)
+			} else {
+				rebuild = true
+			}
+		}
+		if rebuild {
+			instance, err := spec.create(res, key)
 			if err != nil {
 				return nil, err
 			}
 			if instance == nil {
-				return nil, errors.NewError("Resolver returned a nil instance")
+				return nil, res.fail(errors.ErrNilInstance, key, "Resolver returned a nil instance")
 			}
-			spec.instance = instance
+			previous := spec.instance
+			if !spec.cache(res, key, instance) {
+				spec.container.closeDiscarded(spec, key, instance)
+				return nil, ErrClosed
+			}
+			spec.container.retire(spec, key, previous)
 		}
 
-		return spec.instance, nil
+		return decorate(res, key, spec.instance, &spec.decorations)
 	case TRANSIENT:
-		return spec.executeResolver()
+		instance, err := spec.create(res, key)
+		if err != nil {
+			return nil, err
+		}
+		if instance == nil {
+			return nil, res.fail(errors.ErrNilInstance, key, "Resolver returned a nil instance")
+		}
+		return decorate(res, key, instance, nil)
+	case SCOPED:
+		return res.container.resolveScoped(res, key, spec)
 	default:
 		return nil, errors.Errorf("abstraction lifecycle not valid")
 	}
 
 }
 
-func (spec *dependencySpec) executeResolver() (any, error) {
-	resolverArguments, err := spec.arguments()
-	if err != nil {
-		return nil, err
+func (spec *dependencySpec) executeResolver(res *resolution) (any, error) {
+	if spec.structType != nil {
+		return spec.construct(res)
 	}
 
-	resolverValue := reflect.ValueOf(spec.resolver)
-	returnedValues := resolverValue.Call(resolverArguments)
+	var returnedValues []reflect.Value
+	plan := spec.plan.Load()
+	if plan != nil {
+		var err error
+		returnedValues, err = plan.call(res)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		resolverArguments, err := spec.arguments(res)
+		if err != nil {
+			return nil, err
+		}
+		returnedValues = reflect.ValueOf(spec.resolver).Call(resolverArguments)
+	}
 
 	instanceValue := returnedValues[0]
 	instance := instanceValue.Interface()
 
 	if len(returnedValues) == 2 {
 		errValue := returnedValues[1]
-		if errValue.IsNil() {
-			err = nil
-		} else {
-			err = errValue.Interface().(error)
+		if !errValue.IsNil() {
+			key := res.current()
+			err := errValue.Interface().(error)
+			return instance, res.fail(errors.ErrResolverFailed, key, "resolver of %s failed: %w", key, err)
 		}
 	}
 
-	return instance, err
+	return instance, nil
 }
 
-func (spec *dependencySpec) arguments() ([]reflect.Value, error) {
+// dependency is a dependency requested by a resolver, identified either by type or by token
+type dependency struct {
+	refType reflect.Type
+	token   string
+	// optional dependencies are left at their zero value when they are not registered
+	optional bool
+	// deferredType is the Lazy or provider type the dependency is requested with. The
+	// dependency is resolved when it is used instead of when it is requested.
+	deferredType reflect.Type
+}
+
+// newDependency creates the dependency of a value of refType, identified by the token when it
+// is set. Lazy and provider types depend on the type they resolve.
+func newDependency(refType reflect.Type, token string) dependency {
+	target, isDeferred := deferredTarget(refType)
+	switch {
+	case isDeferred:
+		return dependency{refType: target, token: token, deferredType: refType}
+	case token != "":
+		return dependency{token: token}
+	default:
+		return dependency{refType: refType}
+	}
+}
+
+func (dep dependency) key() string {
+	if dep.token != "" {
+		return tokenKey(dep.token)
+	}
+	return typeKey(dep.refType)
+}
+
+// dependencies returns the dependencies the resolver needs to be executed with the registry.
+// The fields of parameter objects are returned as dependencies of the resolver.
+func (spec *dependencySpec) dependencies(r *registry) []dependency {
+	if spec.structType != nil {
+		return fieldDependencies(r, spec.structType, nil)
+	}
+
+	resolverType := reflect.TypeOf(spec.resolver)
+	dependencies := make([]dependency, 0, resolverType.NumIn())
+	for i := 0; i < resolverType.NumIn(); i++ {
+		dependencies = append(dependencies, argumentDependencies(r, resolverType.In(i))...)
+	}
+	return dependencies
+}
+
+// argumentDependencies returns the dependencies needed to resolve an argument of a resolver
+func argumentDependencies(r *registry, inType reflect.Type) []dependency {
+	// The context is provided by the caller
+	if inType == contextType {
+		return nil
+	}
+	if isParameterObject(inType) {
+		return fieldDependencies(r, inType, nil)
+	}
+	return []dependency{newDependency(inType, "")}
+}
+
+func (spec *dependencySpec) arguments(res *resolution) ([]reflect.Value, error) {
 	resolverType := reflect.TypeOf(spec.resolver)
 	values := make([]reflect.Value, resolverType.NumIn())
 
 	for i := 0; i < resolverType.NumIn(); i++ {
-		value, err := spec.container.resolveType(resolverType.In(i))
+		value, err := resolveArgument(resolverType.In(i), res)
 		if err != nil {
 			return nil, err
 		}
-		values[i] = reflect.ValueOf(value)
+		values[i] = value
 	}
 	return values, nil
 }
 
+// resolveArgument resolves an argument of a resolver. Parameter objects are filled
+// and the context is the one of the resolution.
+func resolveArgument(inType reflect.Type, res *resolution) (reflect.Value, error) {
+	if inType == contextType {
+		return reflect.ValueOf(&res.ctx).Elem(), nil
+	}
+	if isParameterObject(inType) {
+		params := reflect.New(inType).Elem()
+		err := res.container.fillStruct(params, res)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return params, nil
+	}
+	value, err := res.container.resolveDependency(newDependency(inType, ""), res)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(value), nil
+}
+
 func newSpec(resolver any, lifeCycle abstractionLifeCycle, container *wireContainer) (spec *dependencySpec, err error) {
 	spec = new(dependencySpec)
 	spec.lifeCycle = lifeCycle
 	spec.container = container
+	spec.source = registrationSource()
 
 	// Get return type of the function
 	resolverType := reflect.TypeOf(resolver)
 	if resolverType.Kind() != reflect.Func {
-		err = errors.NewError("resolver not valid it should be a function")
+		err = errors.NewError("resolver not valid it should be a function").WithKind(errors.ErrInvalidResolver)
 		return
 	}
 
 	numOut := resolverType.NumOut()
 	if numOut < 1 || numOut > 2 {
-		err = errors.NewError("resolver should return between 1-2")
+		err = errors.NewError("resolver should return between 1-2").WithKind(errors.ErrInvalidResolver)
 		return
 	}
 
 	returnType := resolverType.Out(0)
 	spec.returnType = returnType
 	if returnType.Implements(reflect.TypeFor[error]()) {
-		err = errors.NewError("error cannot be the first resolver return type")
+		err = errors.NewError("error cannot be the first resolver return type").WithKind(errors.ErrInvalidResolver)
 		return
 	}
 	spec.resolver = resolver
@@ -121,7 +301,7 @@ func newSpec(resolver any, lifeCycle abstractionLifeCycle, container *wireContai
 		// Check if second return type is error
 		secondType := resolverType.Out(1)
 		if !secondType.Implements(reflect.TypeFor[error]()) {
-			err = errors.NewError("second return type of resolver is not an error")
+			err = errors.NewError("second return type of resolver is not an error").WithKind(errors.ErrInvalidResolver)
 			return
 		}
 	}
@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"context"
 	"reflect"
 	"sync"
 	"sync/atomic"
@@ -101,7 +102,7 @@ func TestExecuteResolver(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, spec)
 
-	instance, err := spec.executeResolver()
+	instance, err := spec.executeResolver(newResolution(context.Background(), container.(*wireContainer)))
 	require.NoError(t, err)
 	abstraction, ok := instance.(mocks.Abstraction)
 	require.True(t, ok)
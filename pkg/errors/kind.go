@@ -0,0 +1,56 @@
+package errors
+
+import "fmt"
+
+// Kind classifies a [WiringError]. Kinds are sentinel errors, use [errors.Is] to check
+// the kind of an error:
+//
+//	if errors.Is(err, wiringErrors.ErrNotRegistered) { ... }
+type Kind uint8
+
+const (
+	// ErrUnknown is the kind of errors that are not classified
+	ErrUnknown Kind = iota
+	// ErrNotRegistered the requested type or token has no resolver
+	ErrNotRegistered
+	// ErrResolverFailed the resolver returned an error
+	ErrResolverFailed
+	// ErrNilInstance the resolver returned a nil instance
+	ErrNilInstance
+	// ErrWrongType the resolved instance cannot be assigned to the requested value
+	ErrWrongType
+	// ErrCircularDependency the dependency depends on itself
+	ErrCircularDependency
+	// ErrInvalidResolver the resolver does not have a valid signature
+	ErrInvalidResolver
+	// ErrCancelled the context of the resolution was cancelled
+	ErrCancelled
+	// ErrOutOfScope a scoped dependency was resolved outside a scope
+	ErrOutOfScope
+)
+
+// Error implements error.
+func (k Kind) Error() string {
+	switch k {
+	case ErrUnknown:
+		return "unknown error"
+	case ErrNotRegistered:
+		return "not registered"
+	case ErrResolverFailed:
+		return "resolver failed"
+	case ErrNilInstance:
+		return "nil instance"
+	case ErrWrongType:
+		return "wrong type"
+	case ErrCircularDependency:
+		return "circular dependency"
+	case ErrInvalidResolver:
+		return "invalid resolver"
+	case ErrCancelled:
+		return "resolution cancelled"
+	case ErrOutOfScope:
+		return "out of scope"
+	default:
+		return fmt.Sprintf("kind(%d)", uint8(k))
+	}
+}
@@ -5,14 +5,29 @@ import (
 	"fmt"
 )
 
+// WiringError is the error returned by the container. Besides the message it holds
+// the [Kind] of the error, the key (type or quoted token) that was requested and the
+// chain of keys that were being resolved when the error happened.
 type WiringError struct {
 	error
+	// Kind classifies the error
+	Kind Kind
+	// Key is the type or token whose resolution failed
+	Key string
+	// Path is the chain of keys being resolved when the error happened, the last one is Key
+	Path []string
 }
 
 func (e *WiringError) Unwrap() error {
 	return e.error
 }
 
+// Is reports if the error is of the provided [Kind]
+func (e *WiringError) Is(target error) bool {
+	kind, ok := target.(Kind)
+	return ok && kind != ErrUnknown && kind == e.Kind
+}
+
 func WrapError(err error) *WiringError {
 	wiringError := new(WiringError)
 	wiringError.error = err
@@ -24,9 +39,37 @@ func NewError(message string) *WiringError {
 	return WrapError(err)
 }
 
+// Errorf formats the error like [fmt.Errorf]. When another [WiringError] is wrapped
+// its kind, key and path are inherited.
 func Errorf(message string, arguments ...any) *WiringError {
 	err := fmt.Errorf(message, arguments...)
-	return WrapError(err)
+	wiringError := WrapError(err)
+
+	var wrapped *WiringError
+	if errors.As(err, &wrapped) {
+		wiringError.Kind = wrapped.Kind
+		wiringError.Key = wrapped.Key
+		wiringError.Path = wrapped.Path
+	}
+	return wiringError
+}
+
+// WithKind sets the kind of the error
+func (e *WiringError) WithKind(kind Kind) *WiringError {
+	e.Kind = kind
+	return e
+}
+
+// WithKey sets the key whose resolution failed
+func (e *WiringError) WithKey(key string) *WiringError {
+	e.Key = key
+	return e
+}
+
+// WithPath sets the chain of keys being resolved when the error happened
+func (e *WiringError) WithPath(path []string) *WiringError {
+	e.Path = path
+	return e
 }
 
 func (err *WiringError) Error() string {
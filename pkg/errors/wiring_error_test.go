@@ -26,3 +26,29 @@ func TestErrorf(t *testing.T) {
 		require.ErrorIs(t, wiringError, err)
 	})
 }
+
+func TestKind(t *testing.T) {
+	t.Run("should match the kind with errors.Is", func(t *testing.T) {
+		wiringError := NewError("resolver for type 'io.Reader' not set").WithKind(ErrNotRegistered).WithKey("io.Reader")
+		require.ErrorIs(t, wiringError, ErrNotRegistered)
+		require.NotErrorIs(t, wiringError, ErrNilInstance)
+		require.NotErrorIs(t, NewError("unclassified"), ErrUnknown)
+		require.Equal(t, "not registered", ErrNotRegistered.Error())
+	})
+	t.Run("should inherit kind, key and path of wrapped errors", func(t *testing.T) {
+		cause := errors.New("connection refused")
+		inner := Errorf("resolver of *sql.DB failed: %w", cause).
+			WithKind(ErrResolverFailed).
+			WithKey("*sql.DB").
+			WithPath([]string{"*Repository", "*sql.DB"})
+		outer := Errorf("error resolving field 'DB': %w", inner)
+
+		require.ErrorIs(t, outer, ErrResolverFailed)
+		require.ErrorIs(t, outer, cause)
+		var wiringError *WiringError
+		require.ErrorAs(t, outer, &wiringError)
+		require.Equal(t, ErrResolverFailed, wiringError.Kind)
+		require.Equal(t, "*sql.DB", wiringError.Key)
+		require.Equal(t, []string{"*Repository", "*sql.DB"}, wiringError.Path)
+	})
+}
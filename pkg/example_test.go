@@ -132,3 +132,19 @@ func ExampleContainer_fill() {
 	impl.Impl.Greet()
 	// Output: Hello world
 }
+
+func ExampleGet() {
+	var container = wiring.New()
+	err := wiring.ProvideSingleton[Abstraction](container, func() (Abstraction, error) {
+		return &Implementation{}, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	impl, err := wiring.Get[Abstraction](container)
+	if err != nil {
+		panic(err)
+	}
+	impl.Greet()
+	// Output: Hello world
+}
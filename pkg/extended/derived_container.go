@@ -1,8 +1,17 @@
 package extended
 
 import (
-	"github.com/4strodev/wiring/pkg"
+	"context"
+	"errors"
 	"reflect"
+
+	"github.com/4strodev/wiring/pkg"
+	wiringErrors "github.com/4strodev/wiring/pkg/errors"
+)
+
+var (
+	contextType = reflect.TypeFor[context.Context]()
+	errorType   = reflect.TypeFor[error]()
 )
 
 // DerivedContainer allows you to create containers that inherits resolvers from parent containers.
@@ -15,12 +24,18 @@ type DerivedContainer struct {
 
 // Fill implements pkg.Container.
 func (d *DerivedContainer) Fill(structure any) error {
-	err := d.Container.Fill(structure)
-	if err != nil {
-		return d.parent.Fill(structure)
-	}
+	return d.FillContext(context.Background(), structure)
+}
 
-	return nil
+// FillContext implements pkg.Container.
+func (d *DerivedContainer) FillContext(ctx context.Context, structure any) error {
+	err := d.Container.FillContext(ctx, structure)
+	// Only dependencies missing on the derived container are resolved from the parent,
+	// other errors like a closed container or a failing resolver are returned
+	if errors.Is(err, wiringErrors.ErrNotRegistered) {
+		return d.parent.FillContext(ctx, structure)
+	}
+	return err
 }
 
 // HasToken implements pkg.Container.
@@ -35,22 +50,185 @@ func (d *DerivedContainer) HasType(refType reflect.Type) bool {
 
 // Resolve implements pkg.Container.
 func (d *DerivedContainer) Resolve(value any) error {
-	err := d.Container.Resolve(value)
-	if err != nil {
-		return d.parent.Resolve(value)
+	return d.ResolveContext(context.Background(), value)
+}
+
+// ResolveContext implements pkg.Container.
+func (d *DerivedContainer) ResolveContext(ctx context.Context, value any) error {
+	err := d.Container.ResolveContext(ctx, value)
+	if errors.Is(err, wiringErrors.ErrNotRegistered) {
+		return d.parent.ResolveContext(ctx, value)
+	}
+	return err
+}
+
+// Invalidate implements pkg.Container. Singletons registered on the parent are invalidated on the parent.
+func (d *DerivedContainer) Invalidate(refType reflect.Type) error {
+	err := d.Container.Invalidate(refType)
+	if errors.Is(err, wiringErrors.ErrNotRegistered) {
+		return d.parent.Invalidate(refType)
 	}
+	return err
+}
 
-	return nil
+// InvalidateToken implements pkg.Container.
+func (d *DerivedContainer) InvalidateToken(token string) error {
+	err := d.Container.InvalidateToken(token)
+	if errors.Is(err, wiringErrors.ErrNotRegistered) {
+		return d.parent.InvalidateToken(token)
+	}
+	return err
+}
+
+// Swap implements pkg.Container. Dependencies registered on the parent are swapped on the parent.
+func (d *DerivedContainer) Swap(resolver any) error {
+	err := d.Container.Swap(resolver)
+	if errors.Is(err, wiringErrors.ErrNotRegistered) {
+		return d.parent.Swap(resolver)
+	}
+	return err
+}
+
+// SwapToken implements pkg.Container.
+func (d *DerivedContainer) SwapToken(token string, resolver any) error {
+	err := d.Container.SwapToken(token, resolver)
+	if errors.Is(err, wiringErrors.ErrNotRegistered) {
+		return d.parent.SwapToken(token, resolver)
+	}
+	return err
+}
+
+// Subscribe implements pkg.Container. The subscriber receives the changes of the parent as well.
+func (d *DerivedContainer) Subscribe(subscriber func(event pkg.ChangeEvent)) (unsubscribe func()) {
+	unsubscribeDerived := d.Container.Subscribe(subscriber)
+	unsubscribeParent := d.parent.Subscribe(subscriber)
+	return func() {
+		unsubscribeDerived()
+		unsubscribeParent()
+	}
 }
 
 // ResolveToken implements pkg.Container.
 func (d *DerivedContainer) ResolveToken(token string, value any) error {
-	err := d.Container.ResolveToken(token, value)
-	if err != nil {
-		return d.parent.ResolveToken(token, value)
+	return d.ResolveTokenContext(context.Background(), token, value)
+}
+
+// ResolveTokenContext implements pkg.Container.
+func (d *DerivedContainer) ResolveTokenContext(ctx context.Context, token string, value any) error {
+	err := d.Container.ResolveTokenContext(ctx, token, value)
+	if errors.Is(err, wiringErrors.ErrNotRegistered) {
+		return d.parent.ResolveTokenContext(ctx, token, value)
 	}
+	return err
+}
+
+// Decorate implements pkg.Container. Decorators of dependencies registered on the parent are only
+// applied when they are resolved from the derived container, the parent never sees them. Parent
+// singletons are decorated once and cached by the derived container, which never closes them.
+// Other dependencies are resolved from the parent every time and decorated on each resolution.
+func (d *DerivedContainer) Decorate(decorator any) error {
+	decoratedType, ok := decoratedType(decorator)
+	if ok && !d.Container.HasType(decoratedType) && d.parent.HasType(decoratedType) {
+		resolver := forwardResolver(decoratedType, d.parent.ResolveContext)
+		err := d.forward(resolver, func(registration pkg.Registration) bool {
+			return registration.Type == decoratedType && registration.Token == "" && registration.Group == ""
+		}, d.Container.Singleton, d.Container.Transient)
+		if err != nil {
+			return err
+		}
+	}
+	return d.Container.Decorate(decorator)
+}
+
+// DecorateToken implements pkg.Container. It works like Decorate for token based dependencies.
+func (d *DerivedContainer) DecorateToken(token string, decorator any) error {
+	decoratedType, ok := decoratedType(decorator)
+	if ok && !d.Container.HasToken(token) && d.parent.HasToken(token) {
+		resolve := func(ctx context.Context, value any) error {
+			return d.parent.ResolveTokenContext(ctx, token, value)
+		}
+		singleton := func(resolver any, options ...pkg.RegistrationOption) error {
+			return d.Container.SingletonToken(token, resolver, options...)
+		}
+		transient := func(resolver any, options ...pkg.RegistrationOption) error {
+			return d.Container.TransientToken(token, resolver, options...)
+		}
+		err := d.forward(forwardResolver(decoratedType, resolve), func(registration pkg.Registration) bool {
+			return registration.Token == token
+		}, singleton, transient)
+		if err != nil {
+			return err
+		}
+	}
+	return d.Container.DecorateToken(token, decorator)
+}
+
+// forward registers the resolver forwarding a parent dependency with the lifecycle of the parent
+// registration, singletons are registered unmanaged since the parent owns their instances
+func (d *DerivedContainer) forward(resolver any, parentRegistration func(pkg.Registration) bool, singleton, transient func(resolver any, options ...pkg.RegistrationOption) error) error {
+	for _, registration := range d.parent.Registrations() {
+		if parentRegistration(registration) && registration.LifeCycle == pkg.SINGLETON {
+			return singleton(resolver, pkg.Unmanaged())
+		}
+	}
+	return transient(resolver)
+}
+
+// decoratedType returns the type decorated by the decorator
+func decoratedType(decorator any) (reflect.Type, bool) {
+	decoratorType := reflect.TypeOf(decorator)
+	if decoratorType == nil || decoratorType.Kind() != reflect.Func || decoratorType.NumIn() == 0 {
+		return nil, false
+	}
+	return decoratorType.In(0), true
+}
+
+// forwardResolver creates a resolver like func(context.Context) (T, error) that resolves T using resolve
+func forwardResolver(refType reflect.Type, resolve func(ctx context.Context, value any) error) any {
+	resolverType := reflect.FuncOf([]reflect.Type{contextType}, []reflect.Type{refType, errorType}, false)
+	return reflect.MakeFunc(resolverType, func(arguments []reflect.Value) []reflect.Value {
+		value := reflect.New(refType)
+		err := resolve(arguments[0].Interface().(context.Context), value.Interface())
+		if err != nil {
+			return []reflect.Value{value.Elem(), reflect.ValueOf(&err).Elem()}
+		}
+		return []reflect.Value{value.Elem(), reflect.Zero(errorType)}
+	}).Interface()
+}
+
+// Registrations implements pkg.Container. The registrations of the derived container are
+// followed by the registrations of the parent that are not overridden.
+func (d *DerivedContainer) Registrations() []pkg.Registration {
+	registrations := d.Container.Registrations()
+	overridden := make(map[string]bool, len(registrations))
+	for _, registration := range registrations {
+		overridden[registration.Key] = true
+	}
+	for _, registration := range d.parent.Registrations() {
+		if !overridden[registration.Key] {
+			registrations = append(registrations, registration)
+		}
+	}
+	return registrations
+}
+
+// Validate implements pkg.Container.
+func (d *DerivedContainer) Validate() error {
+	return errors.Join(d.Container.Validate(), d.parent.Validate())
+}
+
+// NewScope implements pkg.Container. The scope of a derived container is derived from a scope of the parent.
+func (d *DerivedContainer) NewScope() pkg.Container {
+	return &DerivedContainer{
+		parent:    d.parent.NewScope(),
+		Container: d.Container.NewScope(),
+	}
+}
 
-	return nil
+// Close implements pkg.Container. It only closes the instances created by the derived
+// container, the instances of the parent are left untouched.
+func (d *DerivedContainer) Close(ctx context.Context) error {
+	return d.Container.Close(ctx)
 }
 
 func Derived(parent pkg.Container) pkg.Container {
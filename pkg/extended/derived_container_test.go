@@ -1 +1,152 @@
 package extended_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/4strodev/wiring/pkg"
+	wiringErrors "github.com/4strodev/wiring/pkg/errors"
+	"github.com/4strodev/wiring/pkg/extended"
+	"github.com/stretchr/testify/require"
+)
+
+type closer struct {
+	closed atomic.Bool
+}
+
+func (c *closer) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+func TestDerivedClose(t *testing.T) {
+	parent := pkg.New()
+	parentCloser := &closer{}
+	err := parent.Singleton(func() io.Closer {
+		return parentCloser
+	})
+	require.NoError(t, err)
+
+	derived := extended.Derived(parent)
+	derivedCloser := &closer{}
+	err = derived.SingletonToken("closer", func() io.Closer {
+		return derivedCloser
+	})
+	require.NoError(t, err)
+
+	var value io.Closer
+	require.NoError(t, derived.Resolve(&value))
+	require.NoError(t, derived.ResolveToken("closer", &value))
+
+	require.NoError(t, derived.Close(context.Background()))
+	require.True(t, derivedCloser.closed.Load())
+	require.False(t, parentCloser.closed.Load())
+
+	require.ErrorIs(t, derived.Resolve(&value), pkg.ErrClosed)
+	require.NoError(t, parent.Resolve(&value))
+}
+
+type named struct {
+	name string
+}
+
+func TestDerivedDecorate(t *testing.T) {
+	newParent := func(t *testing.T) pkg.Container {
+		parent := pkg.New()
+		err := parent.Singleton(func() *named {
+			return &named{name: "parent"}
+		})
+		require.NoError(t, err)
+		err = parent.SingletonToken("name", func() string {
+			return "parent"
+		})
+		require.NoError(t, err)
+		return parent
+	}
+
+	t.Run("should decorate parent dependencies only on the derived container", func(t *testing.T) {
+		parent := newParent(t)
+		derived := extended.Derived(parent)
+		err := derived.Decorate(func(value *named) *named {
+			return &named{name: "derived " + value.name}
+		})
+		require.NoError(t, err)
+		err = derived.DecorateToken("name", func(value string) string {
+			return "derived " + value
+		})
+		require.NoError(t, err)
+
+		decorated, err := pkg.Get[*named](derived)
+		require.NoError(t, err)
+		require.Equal(t, "derived parent", decorated.name)
+		name, err := pkg.GetToken[string](derived, "name")
+		require.NoError(t, err)
+		require.Equal(t, "derived parent", name)
+
+		original, err := pkg.Get[*named](parent)
+		require.NoError(t, err)
+		require.Equal(t, "parent", original.name)
+		name, err = pkg.GetToken[string](parent, "name")
+		require.NoError(t, err)
+		require.Equal(t, "parent", name)
+	})
+	t.Run("should cache decorated parent singletons", func(t *testing.T) {
+		parent := pkg.New()
+		parentCloser := &closer{}
+		require.NoError(t, parent.Singleton(func() *closer { return parentCloser }))
+		derived := extended.Derived(parent)
+		calls := 0
+		require.NoError(t, derived.Decorate(func(value *closer) *closer {
+			calls++
+			return &closer{}
+		}))
+
+		a, err := pkg.Get[*closer](derived)
+		require.NoError(t, err)
+		b, err := pkg.Get[*closer](derived)
+		require.NoError(t, err)
+		require.Same(t, a, b)
+		require.Equal(t, 1, calls)
+
+		// The parent instance is owned by the parent
+		require.NoError(t, derived.Close(context.Background()))
+		require.False(t, parentCloser.closed.Load())
+	})
+	t.Run("should not fall back to the parent when a decorator fails", func(t *testing.T) {
+		derived := extended.Derived(newParent(t))
+		decoratorErr := errors.New("decorator failed")
+		err := derived.Decorate(func(value *named) (*named, error) {
+			return nil, decoratorErr
+		})
+		require.NoError(t, err)
+
+		_, err = pkg.Get[*named](derived)
+		require.ErrorIs(t, err, decoratorErr)
+	})
+}
+
+func TestDerivedInvalidate(t *testing.T) {
+	parent := pkg.New()
+	var parentClosers []*closer
+	require.NoError(t, parent.Singleton(func() *closer {
+		parentClosers = append(parentClosers, &closer{})
+		return parentClosers[len(parentClosers)-1]
+	}))
+
+	derived := extended.Derived(parent)
+	_, err := pkg.Get[*closer](derived)
+	require.NoError(t, err)
+
+	require.NoError(t, derived.Invalidate(reflect.TypeFor[*closer]()))
+	_, err = pkg.Get[*closer](derived)
+	require.NoError(t, err)
+	require.Len(t, parentClosers, 2)
+	require.Eventually(t, parentClosers[0].closed.Load, time.Second, time.Millisecond)
+
+	require.ErrorIs(t, derived.InvalidateToken("missing"), wiringErrors.ErrNotRegistered)
+}
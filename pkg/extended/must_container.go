@@ -1,7 +1,10 @@
 package extended
 
 import (
+	"context"
 	"reflect"
+
+	"github.com/4strodev/wiring/pkg"
 )
 
 // MustContainer is a container which instead of returing errors it panics
@@ -13,34 +16,96 @@ type MustContainer interface {
 	// Singleton sets a dependency as a [wiring.] dependency.
 	// Once the abstraction is instanciated this instance will be cached and
 	// will no longer create new instances
-	Singleton(resolver any)
+	Singleton(resolver any, options ...pkg.RegistrationOption)
 	// Transient sets a dependency as a transient dependency.
 	// Every time the container is asked to resolve an abstraction
 	// the container will create a new instance of that dependency
-	Transient(resolver any)
+	Transient(resolver any, options ...pkg.RegistrationOption)
+	// Scoped sets a dependency as a scoped dependency.
+	// Every scope created with NewScope caches its own instance
+	Scoped(resolver any, options ...pkg.RegistrationOption)
+	// Replace replaces the resolver of the type returned by the resolver keeping its lifecycle
+	Replace(resolver any)
+	// Swap replaces the resolver of the type returned by the resolver at runtime
+	Swap(resolver any)
+	// Decorate adds a decorator like func(T, dependencies...) (T, error) applied every time T is resolved
+	Decorate(decorator any)
+	// Invalidate discards the instance of a singleton so it is rebuilt
+	Invalidate(refType reflect.Type)
+	// SingletonStruct sets a singleton struct built by the container
+	SingletonStruct(refType reflect.Type, options ...pkg.RegistrationOption)
+	// TransientStruct same as SingletonStruct but with a transient lifecycle
+	TransientStruct(refType reflect.Type, options ...pkg.RegistrationOption)
 	// Resolve given a pointer to value it will be resolved and the container
 	// will update the referenced value with the instance resolved
 	Resolve(value any)
+	// ResolveContext same as Resolve but the context is injected in every resolver of the chain
+	ResolveContext(ctx context.Context, value any)
+
+	// Group based injection
+
+	// SingletonGroup adds a singleton dependency to the group of the type returned by the resolver
+	SingletonGroup(name string, resolver any)
+	// TransientGroup same as SingletonGroup but the member has a transient lifecycle
+	TransientGroup(name string, resolver any)
 
 	// Token based injection
 
 	// SingletonToken same as Singleton but instead of using the type to identify
 	// the implementation it uses the token
-	SingletonToken(token string, resolver any)
+	SingletonToken(token string, resolver any, options ...pkg.RegistrationOption)
 	// TransientToken same as Transient but instead of using the type to identify
 	// the implementation it uses the token
-	TransientToken(token string, resolver any)
+	TransientToken(token string, resolver any, options ...pkg.RegistrationOption)
+	// ScopedToken same as Scoped but instead of using the type to identify
+	// the implementation it uses the token
+	ScopedToken(token string, resolver any, options ...pkg.RegistrationOption)
+	// ReplaceToken same as Replace but for token based dependencies
+	ReplaceToken(token string, resolver any)
+	// SwapToken same as Swap but for token based dependencies
+	SwapToken(token string, resolver any)
+	// DecorateToken same as Decorate but for token based dependencies
+	DecorateToken(token string, decorator any)
+	// InvalidateToken same as Invalidate but for token based dependencies
+	InvalidateToken(token string)
 	// Gets the instance associated with the provided token
 	ResolveToken(token string, value any)
+	// ResolveTokenContext same as ResolveToken but injecting the context like ResolveContext
+	ResolveTokenContext(ctx context.Context, token string, value any)
 
 	// Fill gets a struct pointer and resolves their fields, if the field needs to be resolved by token
 	// you can use the 'wire' tag with the token that is associated with. If the field needs to be ignored
-	// use the ignore param -> wire:",ignore". Unexported fields will be ignored
+	// use the ignore param -> wire:",ignore". Unexported fields will be ignored.
+	// Fields tagged with wire:",optional" are left empty and fields tagged with wire:",fill" are filled recursively
 	Fill(structure any)
+	// FillContext same as Fill but injecting the context like ResolveContext
+	FillContext(ctx context.Context, structure any)
 
 	// Check if the container has a resolver for that type
 	HasType(refType reflect.Type) bool
 	// Check if the container has a resolver for that token
 	HasToken(token string) bool
+
+	// Registrations describes every dependency registered on the container
+	Registrations() []pkg.Registration
+
+	// Subscribe calls the subscriber every time a dependency is swapped
+	Subscribe(subscriber func(event pkg.ChangeEvent)) (unsubscribe func())
+
+	// Validate checks the whole dependency graph without executing any resolver
+	Validate()
+
+	// NewScope creates a scope that shares the resolvers of the container
+	NewScope() MustContainer
+
+	// Build validates the dependency graph and compiles the way every resolver is called.
+	// Once built no more dependencies can be registered on the container.
+	Build()
+
+	// WarmUp instantiates every eager singleton
+	WarmUp(ctx context.Context)
+
+	// Close releases every singleton instantiated by the container
+	Close(ctx context.Context)
 }
 
@@ -1,8 +1,10 @@
 package extended
 
 import (
-	"github.com/4strodev/wiring/pkg"
+	"context"
 	"reflect"
+
+	"github.com/4strodev/wiring/pkg"
 )
 
 type mustContainer struct {
@@ -17,6 +19,14 @@ func (m *mustContainer) Fill(structure any) {
 	}
 }
 
+// FillContext implements MustContainer.
+func (m *mustContainer) FillContext(ctx context.Context, structure any) {
+	err := m.Container.FillContext(ctx, structure)
+	if err != nil {
+		panic(err)
+	}
+}
+
 // HasToken implements MustContainer.
 func (m *mustContainer) HasToken(token string) bool {
 	return m.Container.HasToken(token)
@@ -27,6 +37,54 @@ func (m *mustContainer) HasType(refType reflect.Type) bool {
 	return m.Container.HasType(refType)
 }
 
+// Replace implements MustContainer.
+func (m *mustContainer) Replace(resolver any) {
+	err := m.Container.Replace(resolver)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Decorate implements MustContainer.
+func (m *mustContainer) Decorate(decorator any) {
+	err := m.Container.Decorate(decorator)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Invalidate implements MustContainer.
+func (m *mustContainer) Invalidate(refType reflect.Type) {
+	err := m.Container.Invalidate(refType)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// InvalidateToken implements MustContainer.
+func (m *mustContainer) InvalidateToken(token string) {
+	err := m.Container.InvalidateToken(token)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// DecorateToken implements MustContainer.
+func (m *mustContainer) DecorateToken(token string, decorator any) {
+	err := m.Container.DecorateToken(token, decorator)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// ReplaceToken implements MustContainer.
+func (m *mustContainer) ReplaceToken(token string, resolver any) {
+	err := m.Container.ReplaceToken(token, resolver)
+	if err != nil {
+		panic(err)
+	}
+}
+
 // Resolve implements MustContainer.
 func (m *mustContainer) Resolve(value any) {
 	err := m.Container.Resolve(value)
@@ -35,6 +93,14 @@ func (m *mustContainer) Resolve(value any) {
 	}
 }
 
+// ResolveContext implements MustContainer.
+func (m *mustContainer) ResolveContext(ctx context.Context, value any) {
+	err := m.Container.ResolveContext(ctx, value)
+	if err != nil {
+		panic(err)
+	}
+}
+
 // ResolveToken implements MustContainer.
 func (m *mustContainer) ResolveToken(token string, value any) {
 	err := m.Container.ResolveToken(token, value)
@@ -43,39 +109,149 @@ func (m *mustContainer) ResolveToken(token string, value any) {
 	}
 }
 
+// ResolveTokenContext implements MustContainer.
+func (m *mustContainer) ResolveTokenContext(ctx context.Context, token string, value any) {
+	err := m.Container.ResolveTokenContext(ctx, token, value)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Scoped implements MustContainer.
+func (m *mustContainer) Scoped(resolver any, options ...pkg.RegistrationOption) {
+	err := m.Container.Scoped(resolver, options...)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// ScopedToken implements MustContainer.
+func (m *mustContainer) ScopedToken(token string, resolver any, options ...pkg.RegistrationOption) {
+	err := m.Container.ScopedToken(token, resolver, options...)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// NewScope implements MustContainer.
+func (m *mustContainer) NewScope() MustContainer {
+	return Must(m.Container.NewScope())
+}
+
 // Singleton implements MustContainer.
-func (m *mustContainer) Singleton(resolver any) {
-	err := m.Container.Singleton(resolver)
+func (m *mustContainer) Singleton(resolver any, options ...pkg.RegistrationOption) {
+	err := m.Container.Singleton(resolver, options...)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// SingletonStruct implements MustContainer.
+func (m *mustContainer) SingletonStruct(refType reflect.Type, options ...pkg.RegistrationOption) {
+	err := m.Container.SingletonStruct(refType, options...)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// TransientStruct implements MustContainer.
+func (m *mustContainer) TransientStruct(refType reflect.Type, options ...pkg.RegistrationOption) {
+	err := m.Container.TransientStruct(refType, options...)
 	if err != nil {
 		panic(err)
 	}
 }
 
 // SingletonToken implements MustContainer.
-func (m *mustContainer) SingletonToken(token string, resolver any) {
-	err := m.Container.SingletonToken(token, resolver)
+func (m *mustContainer) SingletonToken(token string, resolver any, options ...pkg.RegistrationOption) {
+	err := m.Container.SingletonToken(token, resolver, options...)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// SingletonGroup implements MustContainer.
+func (m *mustContainer) SingletonGroup(name string, resolver any) {
+	err := m.Container.SingletonGroup(name, resolver)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// TransientGroup implements MustContainer.
+func (m *mustContainer) TransientGroup(name string, resolver any) {
+	err := m.Container.TransientGroup(name, resolver)
 	if err != nil {
 		panic(err)
 	}
 }
 
 // Transient implements MustContainer.
-func (m *mustContainer) Transient(resolver any) {
-	err := m.Container.Transient(resolver)
+func (m *mustContainer) Transient(resolver any, options ...pkg.RegistrationOption) {
+	err := m.Container.Transient(resolver, options...)
 	if err != nil {
 		panic(err)
 	}
 }
 
 // TransientToken implements MustContainer.
-func (m *mustContainer) TransientToken(token string, resolver any) {
-	err := m.Container.TransientToken(token, resolver)
+func (m *mustContainer) TransientToken(token string, resolver any, options ...pkg.RegistrationOption) {
+	err := m.Container.TransientToken(token, resolver, options...)
 	if err != nil {
 		panic(err)
 	}
 }
 
-func Must(container pkg.Container) MustContainer {
+// Swap implements MustContainer.
+func (m *mustContainer) Swap(resolver any) {
+	err := m.Container.Swap(resolver)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// SwapToken implements MustContainer.
+func (m *mustContainer) SwapToken(token string, resolver any) {
+	err := m.Container.SwapToken(token, resolver)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Validate implements MustContainer.
+func (m *mustContainer) Validate() {
+	err := m.Container.Validate()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Build implements MustContainer.
+func (m *mustContainer) Build() {
+	err := m.Container.Build()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// WarmUp implements MustContainer.
+func (m *mustContainer) WarmUp(ctx context.Context) {
+	err := m.Container.WarmUp(ctx)
+	if err != nil {
+		panic(err)
+	}
+}
 
-	return &mustContainer{}
+// Close implements MustContainer.
+func (m *mustContainer) Close(ctx context.Context) {
+	err := m.Container.Close(ctx)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func Must(container pkg.Container) MustContainer {
+	return &mustContainer{
+		Container: container,
+	}
 }
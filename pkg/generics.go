@@ -0,0 +1,74 @@
+package pkg
+
+import (
+	"reflect"
+
+	"github.com/4strodev/wiring/pkg/errors"
+)
+
+// Get resolves the abstraction T from the container
+func Get[T any](c Container) (T, error) {
+	var value T
+	err := c.Resolve(&value)
+	return value, err
+}
+
+// MustGet same as Get but panics if the abstraction cannot be resolved
+func MustGet[T any](c Container) T {
+	value, err := Get[T](c)
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// GetToken resolves the instance associated with the token as T
+func GetToken[T any](c Container, token string) (T, error) {
+	var value T
+	err := c.ResolveToken(token, &value)
+	return value, err
+}
+
+// ProvideSingleton sets the resolver of T with a singleton lifecycle. The resolver is a function
+// like func(dependencies...) (T, error), its return type is checked when it is registered since
+// resolvers with any dependencies cannot be typed.
+func ProvideSingleton[T any](c Container, resolver any, options ...RegistrationOption) error {
+	err := checkResolverReturns[T](resolver)
+	if err != nil {
+		return err
+	}
+	return c.Singleton(resolver, options...)
+}
+
+// ProvideTransient sets the resolver of T with a transient lifecycle. The resolver is a function
+// like func(dependencies...) (T, error), its return type is checked when it is registered since
+// resolvers with any dependencies cannot be typed.
+func ProvideTransient[T any](c Container, resolver any, options ...RegistrationOption) error {
+	err := checkResolverReturns[T](resolver)
+	if err != nil {
+		return err
+	}
+	return c.Transient(resolver, options...)
+}
+
+// SingletonStruct sets T as a singleton built by the container like [Container.SingletonStruct]
+func SingletonStruct[T any](c Container, options ...RegistrationOption) error {
+	return c.SingletonStruct(reflect.TypeFor[T](), options...)
+}
+
+// TransientStruct sets T as a transient built by the container like [Container.TransientStruct]
+func TransientStruct[T any](c Container, options ...RegistrationOption) error {
+	return c.TransientStruct(reflect.TypeFor[T](), options...)
+}
+
+func checkResolverReturns[T any](resolver any) error {
+	expectedType := reflect.TypeFor[T]()
+	resolverType := reflect.TypeOf(resolver)
+	if resolverType == nil || resolverType.Kind() != reflect.Func {
+		return errors.NewError("resolver not valid it should be a function").WithKind(errors.ErrInvalidResolver)
+	}
+	if resolverType.NumOut() < 1 || resolverType.Out(0) != expectedType {
+		return errors.Errorf("resolver should return '%s' as first return type", expectedType.String()).WithKind(errors.ErrInvalidResolver)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package pkg
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/4strodev/wiring/pkg/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	t.Run("should resolve by type", func(t *testing.T) {
+		container := InitializeContainer(t)
+		abstraction, err := Get[mocks.Abstraction](container)
+		require.NoError(t, err)
+		require.Equal(t, mocks.Resolver(), abstraction)
+	})
+	t.Run("should return error when the type is not set", func(t *testing.T) {
+		container := InitializeContainer(t)
+		reader, err := Get[io.Reader](container)
+		require.Error(t, err)
+		require.Nil(t, reader)
+	})
+	t.Run("should panic on MustGet when the type is not set", func(t *testing.T) {
+		container := InitializeContainer(t)
+		require.NotPanics(t, func() {
+			MustGet[mocks.Abstraction](container)
+		})
+		require.Panics(t, func() {
+			MustGet[io.Reader](container)
+		})
+	})
+}
+
+func TestGetToken(t *testing.T) {
+	container := InitializeContainer(t)
+	message, err := GetToken[string](container, mocks.TESTING_TOKEN)
+	require.NoError(t, err)
+	require.Equal(t, mocks.DEFAULT_MESSAGE, message)
+
+	_, err = GetToken[int](container, mocks.TESTING_TOKEN)
+	require.Error(t, err)
+}
+
+func TestProvide(t *testing.T) {
+	t.Run("should register resolvers returning the provided type", func(t *testing.T) {
+		container := New()
+		err := ProvideSingleton[mocks.Abstraction](container, mocks.Resolver)
+		require.NoError(t, err)
+		err = ProvideTransient[ComplexAbstraction](container, func(abstraction mocks.Abstraction) (ComplexAbstraction, error) {
+			return ComplexImplementation{Abstraction: abstraction}, nil
+		})
+		require.NoError(t, err)
+
+		complexAbstraction, err := Get[ComplexAbstraction](container)
+		require.NoError(t, err)
+		require.NotNil(t, complexAbstraction)
+	})
+	t.Run("should reject resolvers returning another type", func(t *testing.T) {
+		container := New()
+		err := ProvideSingleton[ComplexAbstraction](container, mocks.Resolver)
+		require.EqualError(t, err, "resolver should return 'pkg.ComplexAbstraction' as first return type")
+		err = ProvideTransient[mocks.Abstraction](container, "not a function")
+		require.Error(t, err)
+		require.False(t, container.HasType(reflect.TypeFor[mocks.Abstraction]()))
+	})
+	t.Run("should report invalid resolvers", func(t *testing.T) {
+		container := New()
+		err := ProvideSingleton[mocks.Abstraction](container, func() (mocks.Abstraction, string) {
+			return nil, ""
+		})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,12 @@
+package pkg
+
+import "reflect"
+
+// groupMap holds the groups of dependencies associated to the [reflect.Type] of their members.
+// It is part of a [registry] so neither the map nor its groups are modified once the registry is published.
+type groupMap map[reflect.Type]*dependencyGroup
+
+// sortedTypes returns the types of the groups sorted by their name
+func (m groupMap) sortedTypes() []reflect.Type {
+	return sortedTypes(m)
+}
@@ -0,0 +1,171 @@
+package pkg
+
+import (
+	"context"
+	"time"
+)
+
+// ResolveEvent describes the resolution of a dependency
+type ResolveEvent struct {
+	// Context is the context of the resolution
+	Context context.Context
+	// Key is the name of the dependency, the type name or the quoted token
+	Key       string
+	LifeCycle abstractionLifeCycle
+	// Cached reports if the instance was taken from the cache instead of being created.
+	// It is only set once the dependency is resolved.
+	Cached bool
+	// Duration is the time taken to resolve the dependency, or to create the instance for
+	// InstanceCreated events. It is zero for BeforeResolve events.
+	Duration time.Duration
+	// Err is the error of ResolveFailed events
+	Err error
+	// Instance is the instance created for InstanceCreated events, before being decorated, or the
+	// retired instance for InstanceRetired events
+	Instance any
+}
+
+// Hook observes the resolutions of a container. Hooks are called synchronously while the
+// dependencies are resolved so they should return quickly.
+type Hook interface {
+	// BeforeResolve is called when the resolution of a dependency starts
+	BeforeResolve(event ResolveEvent)
+	// AfterResolve is called when a dependency has been resolved
+	AfterResolve(event ResolveEvent)
+	// InstanceCreated is called when the resolver of a dependency creates a new instance
+	InstanceCreated(event ResolveEvent)
+	// ResolveFailed is called when a dependency cannot be resolved
+	ResolveFailed(event ResolveEvent)
+}
+
+// RetireHook is implemented by hooks that are notified when a singleton instance replaced by a
+// refresh, an invalidation or a swap is retired. It is called once the grace period is over, before
+// the instance is closed and outside any resolution, so it can resolve dependencies.
+type RetireHook interface {
+	// InstanceRetired is called with the retired instance
+	InstanceRetired(event ResolveEvent)
+}
+
+// HookFuncs implements [Hook] and [RetireHook] with functions, the ones that are nil are not called
+type HookFuncs struct {
+	OnBeforeResolve   func(event ResolveEvent)
+	OnAfterResolve    func(event ResolveEvent)
+	OnInstanceCreated func(event ResolveEvent)
+	OnResolveFailed   func(event ResolveEvent)
+	OnInstanceRetired func(event ResolveEvent)
+}
+
+// BeforeResolve implements Hook.
+func (h HookFuncs) BeforeResolve(event ResolveEvent) {
+	if h.OnBeforeResolve != nil {
+		h.OnBeforeResolve(event)
+	}
+}
+
+// AfterResolve implements Hook.
+func (h HookFuncs) AfterResolve(event ResolveEvent) {
+	if h.OnAfterResolve != nil {
+		h.OnAfterResolve(event)
+	}
+}
+
+// InstanceCreated implements Hook.
+func (h HookFuncs) InstanceCreated(event ResolveEvent) {
+	if h.OnInstanceCreated != nil {
+		h.OnInstanceCreated(event)
+	}
+}
+
+// ResolveFailed implements Hook.
+func (h HookFuncs) ResolveFailed(event ResolveEvent) {
+	if h.OnResolveFailed != nil {
+		h.OnResolveFailed(event)
+	}
+}
+
+// InstanceRetired implements RetireHook.
+func (h HookFuncs) InstanceRetired(event ResolveEvent) {
+	if h.OnInstanceRetired != nil {
+		h.OnInstanceRetired(event)
+	}
+}
+
+// WithHook adds a hook that observes every resolution of the container and its scopes
+func WithHook(hook Hook) Option {
+	return func(w *wireContainer) {
+		w.hooks = append(w.hooks, hook)
+	}
+}
+
+// hooks are the hooks of a container
+type hooks []Hook
+
+func (h hooks) beforeResolve(event ResolveEvent) {
+	for _, hook := range h {
+		hook.BeforeResolve(event)
+	}
+}
+
+func (h hooks) afterResolve(event ResolveEvent) {
+	for _, hook := range h {
+		hook.AfterResolve(event)
+	}
+}
+
+func (h hooks) instanceCreated(event ResolveEvent) {
+	for _, hook := range h {
+		hook.InstanceCreated(event)
+	}
+}
+
+func (h hooks) resolveFailed(event ResolveEvent) {
+	for _, hook := range h {
+		hook.ResolveFailed(event)
+	}
+}
+
+func (h hooks) instanceRetired(event ResolveEvent) {
+	for _, hook := range h {
+		if retireHook, ok := hook.(RetireHook); ok {
+			retireHook.InstanceRetired(event)
+		}
+	}
+}
+
+// observe resolves the spec notifying the hooks of the container
+func (spec *dependencySpec) observe(res *resolution, key string) (any, bool, error) {
+	hooks := spec.container.hooks
+	if len(hooks) == 0 {
+		return spec.resolveStep(res, key)
+	}
+
+	event := ResolveEvent{Context: res.ctx, Key: key, LifeCycle: spec.lifeCycle}
+	hooks.beforeResolve(event)
+	start := time.Now()
+	instance, cached, err := spec.resolveStep(res, key)
+	event.Duration = time.Since(start)
+	if err != nil {
+		event.Err = err
+		hooks.resolveFailed(event)
+		return nil, false, err
+	}
+	event.Cached = cached
+	hooks.afterResolve(event)
+	return instance, cached, nil
+}
+
+// create executes the resolver of the spec notifying the hooks of the new instance
+func (spec *dependencySpec) create(res *resolution, key string) (any, error) {
+	res.steps[len(res.steps)-1].created = true
+	hooks := spec.container.hooks
+	if len(hooks) == 0 {
+		return spec.executeWithRetry(res, key)
+	}
+
+	start := time.Now()
+	instance, err := spec.executeWithRetry(res, key)
+	if err == nil {
+		hooks.instanceCreated(ResolveEvent{Context: res.ctx, Key: key, LifeCycle: spec.lifeCycle, Duration: time.Since(start), Instance: instance})
+	}
+	return instance, err
+}
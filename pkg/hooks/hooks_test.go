@@ -0,0 +1,171 @@
+package hooks_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/4strodev/wiring/pkg"
+	"github.com/4strodev/wiring/pkg/hooks"
+	"github.com/stretchr/testify/require"
+)
+
+type service struct{}
+
+// memoryCounter counts in memory the increments of every set of labels
+type memoryCounter map[string]int
+
+func (c memoryCounter) Inc(labelValues ...string) {
+	c[strings.Join(labelValues, ",")]++
+}
+
+// memoryHistogram keeps in memory the observations of every set of labels
+type memoryHistogram map[string][]float64
+
+func (h memoryHistogram) Observe(value float64, labelValues ...string) {
+	key := strings.Join(labelValues, ",")
+	h[key] = append(h[key], value)
+}
+
+func TestSlogHook(t *testing.T) {
+	var output bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&output, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey || attr.Key == "duration" {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	}))
+	container := pkg.New(pkg.WithHook(hooks.NewSlogHook(logger)))
+	require.NoError(t, container.Singleton(func() *service {
+		return &service{}
+	}))
+	require.NoError(t, container.TransientToken("failing", func() (*service, error) {
+		return nil, errors.New("unreachable")
+	}))
+
+	_, err := pkg.Get[*service](container)
+	require.NoError(t, err)
+	_, err = pkg.GetToken[*service](container, "failing")
+	require.Error(t, err)
+
+	require.Equal(t, `level=DEBUG msg="wiring: resolving dependency" key=*hooks_test.service lifecycle=singleton
+level=DEBUG msg="wiring: instance created" key=*hooks_test.service lifecycle=singleton
+level=DEBUG msg="wiring: dependency resolved" key=*hooks_test.service lifecycle=singleton cached=false
+level=DEBUG msg="wiring: resolving dependency" key='failing' lifecycle=transient
+level=ERROR msg="wiring: dependency resolution failed" key='failing' lifecycle=transient error="resolver of 'failing' failed: unreachable"
+`, output.String())
+}
+
+func TestMetricsHook(t *testing.T) {
+	resolutions := memoryCounter{}
+	failures := memoryCounter{}
+	creations := memoryCounter{}
+	resolutionSeconds := memoryHistogram{}
+	var creationSeconds []float64
+	container := pkg.New(pkg.WithHook(hooks.NewMetricsHook(hooks.Metrics{
+		Resolutions:       resolutions,
+		Failures:          failures,
+		Creations:         creations,
+		ResolutionSeconds: resolutionSeconds,
+		CreationSeconds: hooks.HistogramFunc(func(value float64, labelValues ...string) {
+			creationSeconds = append(creationSeconds, value)
+		}),
+	})))
+	require.NoError(t, container.Singleton(func() *service {
+		return &service{}
+	}))
+	require.NoError(t, container.TransientToken("failing", func() (*service, error) {
+		return nil, errors.New("unreachable")
+	}))
+
+	for range 3 {
+		_, err := pkg.Get[*service](container)
+		require.NoError(t, err)
+	}
+	_, err := pkg.GetToken[*service](container, "failing")
+	require.Error(t, err)
+
+	require.Equal(t, memoryCounter{"*hooks_test.service,singleton,miss": 1, "*hooks_test.service,singleton,hit": 2}, resolutions)
+	require.Equal(t, memoryCounter{"'failing',transient": 1}, failures)
+	require.Equal(t, memoryCounter{"*hooks_test.service,singleton": 1}, creations)
+	require.Len(t, resolutionSeconds["*hooks_test.service,singleton"], 3)
+	require.Len(t, creationSeconds, 1)
+}
+
+type repository struct{}
+
+type handler struct{}
+
+func TestMemoryTracer(t *testing.T) {
+	t.Run("should nest the spans following the resolution chain", func(t *testing.T) {
+		tracer := hooks.NewMemoryTracer()
+		container := pkg.New(pkg.WithTracer(tracer))
+		require.NoError(t, container.Singleton(func() *repository { return &repository{} }))
+		require.NoError(t, container.Transient(func(*repository) *handler { return &handler{} }))
+
+		ctx, request := tracer.Start(context.Background(), "request")
+		var h *handler
+		require.NoError(t, container.ResolveContext(ctx, &h))
+		require.NoError(t, container.ResolveContext(ctx, &h))
+		request.End()
+
+		spans := tracer.Spans()
+		require.Len(t, spans, 5)
+		requestSpan := spans[4]
+		require.Equal(t, "request", requestSpan.Name)
+		require.Zero(t, requestSpan.Parent)
+
+		// First resolution creates the repository
+		require.Equal(t, "*hooks_test.repository", spans[0].Name)
+		require.Equal(t, "singleton", spans[0].Attributes[pkg.ATTRIBUTE_LIFECYCLE])
+		require.Equal(t, "miss", spans[0].Attributes[pkg.ATTRIBUTE_CACHE])
+		require.Equal(t, spans[1].ID, spans[0].Parent)
+		require.Equal(t, "*hooks_test.handler", spans[1].Name)
+		require.Equal(t, "*hooks_test.handler", spans[1].Attributes[pkg.ATTRIBUTE_KEY])
+		require.Equal(t, requestSpan.ID, spans[1].Parent)
+
+		// Second resolution uses the cached repository
+		require.Equal(t, "hit", spans[2].Attributes[pkg.ATTRIBUTE_CACHE])
+		require.Equal(t, spans[3].ID, spans[2].Parent)
+		require.Equal(t, "miss", spans[3].Attributes[pkg.ATTRIBUTE_CACHE])
+	})
+
+	t.Run("should pass the context of the span to the resolvers", func(t *testing.T) {
+		tracer := hooks.NewMemoryTracer()
+		container := pkg.New(pkg.WithTracer(tracer))
+		require.NoError(t, container.Singleton(func(ctx context.Context) *repository {
+			_, span := tracer.Start(ctx, "query")
+			span.End()
+			return &repository{}
+		}))
+
+		var r *repository
+		require.NoError(t, container.Resolve(&r))
+		spans := tracer.Spans()
+		require.Len(t, spans, 2)
+		require.Equal(t, "query", spans[0].Name)
+		require.Equal(t, spans[1].ID, spans[0].Parent)
+	})
+
+	t.Run("should record the failures on the span", func(t *testing.T) {
+		tracer := hooks.NewMemoryTracer()
+		container := pkg.New(pkg.WithTracer(tracer))
+		require.NoError(t, container.Singleton(func() (*repository, error) { return nil, errors.New("connection refused") }))
+		require.NoError(t, container.Transient(func(*repository) *handler { return &handler{} }))
+
+		var h *handler
+		require.Error(t, container.Resolve(&h))
+		spans := tracer.Spans()
+		require.Len(t, spans, 2)
+		for _, span := range spans {
+			require.ErrorContains(t, span.Err, "connection refused")
+			require.NotContains(t, span.Attributes, pkg.ATTRIBUTE_CACHE)
+		}
+	})
+}
@@ -0,0 +1,97 @@
+package hooks
+
+import (
+	"github.com/4strodev/wiring/pkg"
+)
+
+// Counter is a counter partitioned by labels, like a prometheus CounterVec
+type Counter interface {
+	Inc(labelValues ...string)
+}
+
+// Histogram is a histogram partitioned by labels, like a prometheus HistogramVec
+type Histogram interface {
+	Observe(value float64, labelValues ...string)
+}
+
+// CounterFunc adapts a function to a [Counter]
+//
+//	resolutions := prometheus.NewCounterVec(opts, []string{"key", "lifecycle", "cache"})
+//	hooks.CounterFunc(func(labelValues ...string) {
+//		resolutions.WithLabelValues(labelValues...).Inc()
+//	})
+type CounterFunc func(labelValues ...string)
+
+// Inc implements Counter.
+func (f CounterFunc) Inc(labelValues ...string) {
+	f(labelValues...)
+}
+
+// HistogramFunc adapts a function to a [Histogram]
+type HistogramFunc func(value float64, labelValues ...string)
+
+// Observe implements Histogram.
+func (f HistogramFunc) Observe(value float64, labelValues ...string) {
+	f(value, labelValues...)
+}
+
+// Metrics are the metrics updated by the hook created with [NewMetricsHook]. Metrics that
+// are nil are not updated. Durations are observed in seconds.
+type Metrics struct {
+	// Resolutions counts the resolved dependencies labelled by key, lifecycle and cache, that is "hit" or "miss"
+	Resolutions Counter
+	// Failures counts the failed resolutions labelled by key and lifecycle
+	Failures Counter
+	// Creations counts the instances created by resolvers labelled by key and lifecycle
+	Creations Counter
+	// ResolutionSeconds observes the time taken to resolve dependencies labelled by key and lifecycle
+	ResolutionSeconds Histogram
+	// CreationSeconds observes the time taken by resolvers to create instances labelled by key and lifecycle
+	CreationSeconds Histogram
+}
+
+// MetricsHook updates metrics with the resolutions of a container
+type MetricsHook struct {
+	metrics Metrics
+}
+
+// NewMetricsHook creates a hook that updates the metrics
+func NewMetricsHook(metrics Metrics) *MetricsHook {
+	return &MetricsHook{metrics: metrics}
+}
+
+// BeforeResolve implements pkg.Hook.
+func (h *MetricsHook) BeforeResolve(event pkg.ResolveEvent) {}
+
+// AfterResolve implements pkg.Hook.
+func (h *MetricsHook) AfterResolve(event pkg.ResolveEvent) {
+	lifeCycle := event.LifeCycle.String()
+	if h.metrics.Resolutions != nil {
+		cache := "miss"
+		if event.Cached {
+			cache = "hit"
+		}
+		h.metrics.Resolutions.Inc(event.Key, lifeCycle, cache)
+	}
+	if h.metrics.ResolutionSeconds != nil {
+		h.metrics.ResolutionSeconds.Observe(event.Duration.Seconds(), event.Key, lifeCycle)
+	}
+}
+
+// InstanceCreated implements pkg.Hook.
+func (h *MetricsHook) InstanceCreated(event pkg.ResolveEvent) {
+	lifeCycle := event.LifeCycle.String()
+	if h.metrics.Creations != nil {
+		h.metrics.Creations.Inc(event.Key, lifeCycle)
+	}
+	if h.metrics.CreationSeconds != nil {
+		h.metrics.CreationSeconds.Observe(event.Duration.Seconds(), event.Key, lifeCycle)
+	}
+}
+
+// ResolveFailed implements pkg.Hook.
+func (h *MetricsHook) ResolveFailed(event pkg.ResolveEvent) {
+	if h.metrics.Failures != nil {
+		h.metrics.Failures.Inc(event.Key, event.LifeCycle.String())
+	}
+}
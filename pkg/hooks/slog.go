@@ -0,0 +1,60 @@
+// Package hooks contains adapters that observe the resolutions of a container, see [pkg.WithHook]
+// and [pkg.WithTracer]
+package hooks
+
+import (
+	"log/slog"
+
+	"github.com/4strodev/wiring/pkg"
+)
+
+// SlogHook logs the resolutions of a container. Resolutions and created instances are logged
+// at debug level and failures at error level.
+type SlogHook struct {
+	logger *slog.Logger
+}
+
+// NewSlogHook creates a hook that logs with the logger, if it is nil the default logger is used
+func NewSlogHook(logger *slog.Logger) *SlogHook {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogHook{logger: logger}
+}
+
+// BeforeResolve implements pkg.Hook.
+func (h *SlogHook) BeforeResolve(event pkg.ResolveEvent) {
+	h.logger.LogAttrs(event.Context, slog.LevelDebug, "wiring: resolving dependency",
+		slog.String("key", event.Key),
+		slog.String("lifecycle", event.LifeCycle.String()),
+	)
+}
+
+// AfterResolve implements pkg.Hook.
+func (h *SlogHook) AfterResolve(event pkg.ResolveEvent) {
+	h.logger.LogAttrs(event.Context, slog.LevelDebug, "wiring: dependency resolved",
+		slog.String("key", event.Key),
+		slog.String("lifecycle", event.LifeCycle.String()),
+		slog.Bool("cached", event.Cached),
+		slog.Duration("duration", event.Duration),
+	)
+}
+
+// InstanceCreated implements pkg.Hook.
+func (h *SlogHook) InstanceCreated(event pkg.ResolveEvent) {
+	h.logger.LogAttrs(event.Context, slog.LevelDebug, "wiring: instance created",
+		slog.String("key", event.Key),
+		slog.String("lifecycle", event.LifeCycle.String()),
+		slog.Duration("duration", event.Duration),
+	)
+}
+
+// ResolveFailed implements pkg.Hook.
+func (h *SlogHook) ResolveFailed(event pkg.ResolveEvent) {
+	h.logger.LogAttrs(event.Context, slog.LevelError, "wiring: dependency resolution failed",
+		slog.String("key", event.Key),
+		slog.String("lifecycle", event.LifeCycle.String()),
+		slog.Duration("duration", event.Duration),
+		slog.Any("error", event.Err),
+	)
+}
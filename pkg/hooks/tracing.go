@@ -0,0 +1,95 @@
+package hooks
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/4strodev/wiring/pkg"
+)
+
+// RecordedSpan is a span recorded by a [MemoryTracer]
+type RecordedSpan struct {
+	ID int
+	// Parent is the ID of the parent span, 0 for root spans
+	Parent     int
+	Name       string
+	Attributes map[string]any
+	Err        error
+	Start      time.Time
+	End        time.Time
+}
+
+// MemoryTracer records in memory the spans of a container, it is meant for tests. Spans are
+// nested with the spans of the tracer found on the context.
+type MemoryTracer struct {
+	mutex  sync.Mutex
+	nextID int
+	spans  []*RecordedSpan
+}
+
+// NewMemoryTracer creates an empty memory tracer
+func NewMemoryTracer() *MemoryTracer {
+	return &MemoryTracer{}
+}
+
+type memorySpanKey struct{}
+
+// Start implements pkg.Tracer.
+func (t *MemoryTracer) Start(ctx context.Context, name string) (context.Context, pkg.Span) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.nextID++
+	span := &RecordedSpan{
+		ID:         t.nextID,
+		Name:       name,
+		Attributes: make(map[string]any),
+		Start:      time.Now(),
+	}
+	if parent, ok := ctx.Value(memorySpanKey{}).(*memorySpan); ok && parent.tracer == t {
+		span.Parent = parent.span.ID
+	}
+	return context.WithValue(ctx, memorySpanKey{}, &memorySpan{tracer: t, span: span}), &memorySpan{tracer: t, span: span}
+}
+
+// Spans returns a copy of the ended spans in the order they ended
+func (t *MemoryTracer) Spans() []RecordedSpan {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	spans := make([]RecordedSpan, 0, len(t.spans))
+	for _, span := range t.spans {
+		spans = append(spans, *span)
+	}
+	return spans
+}
+
+// Reset drops the recorded spans
+func (t *MemoryTracer) Reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.spans = nil
+}
+
+type memorySpan struct {
+	tracer *MemoryTracer
+	span   *RecordedSpan
+}
+
+func (s *memorySpan) SetAttribute(key string, value any) {
+	s.tracer.mutex.Lock()
+	defer s.tracer.mutex.Unlock()
+	s.span.Attributes[key] = value
+}
+
+func (s *memorySpan) RecordError(err error) {
+	s.tracer.mutex.Lock()
+	defer s.tracer.mutex.Unlock()
+	s.span.Err = err
+}
+
+func (s *memorySpan) End() {
+	s.tracer.mutex.Lock()
+	defer s.tracer.mutex.Unlock()
+	s.span.End = time.Now()
+	s.tracer.spans = append(s.tracer.spans, s.span)
+}
@@ -0,0 +1,88 @@
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/4strodev/wiring/pkg/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingHook records the events received as strings
+type recordingHook struct {
+	events []string
+}
+
+func (h *recordingHook) hook() Hook {
+	record := func(name string) func(ResolveEvent) {
+		return func(event ResolveEvent) {
+			h.events = append(h.events, fmt.Sprintf("%s %s %s cached=%t err=%v", name, event.Key, event.LifeCycle, event.Cached, event.Err))
+		}
+	}
+	return HookFuncs{
+		OnBeforeResolve:   record("before"),
+		OnAfterResolve:    record("after"),
+		OnInstanceCreated: record("created"),
+		OnResolveFailed:   record("failed"),
+	}
+}
+
+func TestHooks(t *testing.T) {
+	t.Run("should notify resolutions and created instances", func(t *testing.T) {
+		recorder := &recordingHook{}
+		container := New(WithHook(recorder.hook()))
+		require.NoError(t, container.Singleton(mocks.Resolver))
+		require.NoError(t, container.Transient(func(abstraction mocks.Abstraction) *lazyService {
+			return &lazyService{}
+		}))
+
+		_, err := Get[*lazyService](container)
+		require.NoError(t, err)
+		_, err = Get[*lazyService](container)
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"before *pkg.lazyService transient cached=false err=<nil>",
+			"before mocks.Abstraction singleton cached=false err=<nil>",
+			"created mocks.Abstraction singleton cached=false err=<nil>",
+			"after mocks.Abstraction singleton cached=false err=<nil>",
+			"created *pkg.lazyService transient cached=false err=<nil>",
+			"after *pkg.lazyService transient cached=false err=<nil>",
+			"before *pkg.lazyService transient cached=false err=<nil>",
+			"before mocks.Abstraction singleton cached=false err=<nil>",
+			"after mocks.Abstraction singleton cached=true err=<nil>",
+			"created *pkg.lazyService transient cached=false err=<nil>",
+			"after *pkg.lazyService transient cached=false err=<nil>",
+		}, recorder.events)
+	})
+	t.Run("should notify failures", func(t *testing.T) {
+		recorder := &recordingHook{}
+		container := New(WithHook(recorder.hook()))
+		require.NoError(t, container.Singleton(func() (mocks.Abstraction, error) {
+			return nil, errors.New("unreachable")
+		}))
+
+		_, err := Get[mocks.Abstraction](container)
+		require.Error(t, err)
+		require.Equal(t, []string{
+			"before mocks.Abstraction singleton cached=false err=<nil>",
+			"failed mocks.Abstraction singleton cached=false err=resolver of mocks.Abstraction failed: unreachable",
+		}, recorder.events)
+	})
+	t.Run("should measure the durations", func(t *testing.T) {
+		var resolved, created ResolveEvent
+		container := New(WithHook(HookFuncs{
+			OnAfterResolve:    func(event ResolveEvent) { resolved = event },
+			OnInstanceCreated: func(event ResolveEvent) { created = event },
+		}))
+		require.NoError(t, container.Scoped(mocks.Resolver))
+
+		_, err := Get[mocks.Abstraction](container.NewScope())
+		require.NoError(t, err)
+		require.Equal(t, "mocks.Abstraction", created.Key)
+		require.Equal(t, SCOPED, created.LifeCycle)
+		require.Implements(t, (*mocks.Abstraction)(nil), created.Instance)
+		require.GreaterOrEqual(t, resolved.Duration, created.Duration)
+		require.NotNil(t, resolved.Context)
+	})
+}
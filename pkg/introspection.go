@@ -0,0 +1,186 @@
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+const modulePath = "github.com/4strodev/wiring/"
+
+// Registration describes a dependency registered on a container
+type Registration struct {
+	// Key is the name of the dependency, the type name or the quoted token
+	Key string
+	// Type is the type the dependency is registered with. It is nil for token based dependencies
+	Type reflect.Type
+	// Token is the token the dependency is registered with. It is empty for type based dependencies
+	Token string
+	// Group is the name of the member when the dependency is part of a group. Type holds the
+	// type of the members and the group is resolved as a slice of that type
+	Group     string
+	LifeCycle abstractionLifeCycle
+	// Signature is the signature of the resolver, or the struct type for struct dependencies
+	Signature string
+	// Dependencies are the types requested by the resolver
+	Dependencies []reflect.Type
+	// Tokens are the tokens requested by the resolver through parameter objects
+	Tokens []string
+	// Decorators are the signatures of the decorators applied to the dependency in order
+	Decorators []string
+	// Source is the file:line where the dependency was registered
+	Source string
+	// Instantiated reports if a singleton has already cached its instance
+	Instantiated bool
+}
+
+// String returns the name of the lifecycle
+func (l abstractionLifeCycle) String() string {
+	switch l {
+	case SINGLETON:
+		return "singleton"
+	case TRANSIENT:
+		return "transient"
+	case SCOPED:
+		return "scoped"
+	default:
+		return fmt.Sprintf("lifecycle(%d)", uint8(l))
+	}
+}
+
+// MarshalJSON encodes the registration using the names of the types
+func (r Registration) MarshalJSON() ([]byte, error) {
+	dependencies := make([]string, len(r.Dependencies))
+	for i, dependency := range r.Dependencies {
+		dependencies[i] = dependency.String()
+	}
+	var registrationType string
+	if r.Type != nil {
+		registrationType = r.Type.String()
+	}
+
+	return json.Marshal(struct {
+		Key          string   `json:"key"`
+		Type         string   `json:"type,omitempty"`
+		Token        string   `json:"token,omitempty"`
+		Group        string   `json:"group,omitempty"`
+		LifeCycle    string   `json:"lifecycle"`
+		Signature    string   `json:"signature"`
+		Dependencies []string `json:"dependencies"`
+		Tokens       []string `json:"tokens,omitempty"`
+		Decorators   []string `json:"decorators,omitempty"`
+		Source       string   `json:"source"`
+		Instantiated bool     `json:"instantiated"`
+	}{
+		Key:          r.Key,
+		Type:         registrationType,
+		Token:        r.Token,
+		Group:        r.Group,
+		LifeCycle:    r.LifeCycle.String(),
+		Signature:    r.Signature,
+		Dependencies: dependencies,
+		Tokens:       r.Tokens,
+		Decorators:   r.Decorators,
+		Source:       r.Source,
+		Instantiated: r.Instantiated,
+	})
+}
+
+// Registrations implements pkg.Container.
+func (w *wireContainer) Registrations() []Registration {
+	snapshot := w.snapshot()
+	registrations := make([]Registration, 0, len(snapshot.typeMapping)+len(snapshot.tokenMapping))
+	for _, refType := range snapshot.typeMapping.sortedTypes() {
+		registration := snapshot.typeMapping[refType].registration(snapshot, typeKey(refType))
+		registration.Type = refType
+		registration.Decorators = snapshot.decoratorSignatures(registration.Key)
+		registrations = append(registrations, registration)
+	}
+	for _, token := range snapshot.tokenMapping.sortedTokens() {
+		registration := snapshot.tokenMapping[token].registration(snapshot, tokenKey(token))
+		registration.Token = token
+		registration.Decorators = snapshot.decoratorSignatures(registration.Key)
+		registrations = append(registrations, registration)
+	}
+	for _, refType := range snapshot.groupMapping.sortedTypes() {
+		for _, member := range snapshot.groupMapping[refType].members {
+			registration := member.spec.registration(snapshot, groupKey(refType, member.name))
+			registration.Type = refType
+			registration.Group = member.name
+			registrations = append(registrations, registration)
+		}
+	}
+	return registrations
+}
+
+func (spec *dependencySpec) registration(r *registry, key string) Registration {
+	registration := Registration{
+		Key:          key,
+		LifeCycle:    spec.lifeCycle,
+		Signature:    spec.signature(),
+		Dependencies: []reflect.Type{},
+		Source:       spec.source,
+		Instantiated: spec.instantiated.Load(),
+	}
+	for _, dep := range spec.dependencies(r) {
+		if dep.token != "" {
+			registration.Tokens = append(registration.Tokens, dep.token)
+		} else {
+			registration.Dependencies = append(registration.Dependencies, dep.refType)
+		}
+	}
+	return registration
+}
+
+// WriteJSON writes the registrations as a JSON array
+func WriteJSON(w io.Writer, registrations []Registration) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(registrations)
+}
+
+// WriteDOT writes the dependency graph of the registrations in the Graphviz DOT language.
+// Every registration is a node and every dependency an edge to the node of the requested type.
+func WriteDOT(w io.Writer, registrations []Registration) error {
+	var builder strings.Builder
+	builder.WriteString("digraph wiring {\n")
+	for _, registration := range registrations {
+		fmt.Fprintf(&builder, "\t%q [label=%q];\n", registration.Key, registration.Key+"\n"+registration.LifeCycle.String())
+	}
+	for _, registration := range registrations {
+		if registration.Group != "" {
+			fmt.Fprintf(&builder, "\t%q -> %q;\n", typeKey(reflect.SliceOf(registration.Type)), registration.Key)
+		}
+		for _, dependency := range registration.Dependencies {
+			fmt.Fprintf(&builder, "\t%q -> %q;\n", registration.Key, typeKey(dependency))
+		}
+		for _, token := range registration.Tokens {
+			fmt.Fprintf(&builder, "\t%q -> %q;\n", registration.Key, tokenKey(token))
+		}
+	}
+	builder.WriteString("}\n")
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// registrationSource returns the file:line of the first caller outside of this module,
+// that is the place where the dependency was registered.
+func registrationSource() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		internal := strings.HasPrefix(frame.Function, modulePath) && !strings.HasSuffix(frame.File, "_test.go")
+		if !internal {
+			return fmt.Sprintf("%s:%d", frame.File, frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/4strodev/wiring/pkg/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistrations(t *testing.T) {
+	container := InitializeContainer(t)
+	err := container.Transient(func(ctx context.Context, abstraction mocks.Abstraction) ComplexAbstraction {
+		return ComplexImplementation{Abstraction: abstraction}
+	})
+	require.NoError(t, err)
+	var abstraction mocks.Abstraction
+	require.NoError(t, container.Resolve(&abstraction))
+
+	registrations := container.Registrations()
+	require.Len(t, registrations, 3)
+
+	require.Equal(t, "mocks.Abstraction", registrations[0].Key)
+	require.Equal(t, reflect.TypeFor[mocks.Abstraction](), registrations[0].Type)
+	require.Equal(t, SINGLETON, registrations[0].LifeCycle)
+	require.Equal(t, "func() mocks.Abstraction", registrations[0].Signature)
+	require.Empty(t, registrations[0].Dependencies)
+	require.True(t, registrations[0].Instantiated)
+	require.Contains(t, registrations[0].Source, "container_impl_test.go:")
+
+	require.Equal(t, "pkg.ComplexAbstraction", registrations[1].Key)
+	require.Equal(t, TRANSIENT, registrations[1].LifeCycle)
+	require.Equal(t, []reflect.Type{reflect.TypeFor[mocks.Abstraction]()}, registrations[1].Dependencies)
+	require.False(t, registrations[1].Instantiated)
+	require.Contains(t, registrations[1].Source, "introspection_test.go:")
+
+	require.Equal(t, "'token'", registrations[2].Key)
+	require.Nil(t, registrations[2].Type)
+	require.Equal(t, mocks.TESTING_TOKEN, registrations[2].Token)
+	require.False(t, registrations[2].Instantiated)
+}
+
+func TestWriteJSON(t *testing.T) {
+	container := InitializeContainer(t)
+	var buffer bytes.Buffer
+	require.NoError(t, WriteJSON(&buffer, container.Registrations()))
+
+	var decoded []map[string]any
+	require.NoError(t, json.Unmarshal(buffer.Bytes(), &decoded))
+	require.Len(t, decoded, 2)
+	require.Equal(t, "mocks.Abstraction", decoded[0]["type"])
+	require.Equal(t, "singleton", decoded[0]["lifecycle"])
+	require.Equal(t, "token", decoded[1]["token"])
+	require.Equal(t, false, decoded[1]["instantiated"])
+}
+
+func TestWriteDOT(t *testing.T) {
+	container := InitializeContainer(t)
+	err := container.Transient(func(abstraction mocks.Abstraction) ComplexAbstraction {
+		return ComplexImplementation{Abstraction: abstraction}
+	})
+	require.NoError(t, err)
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteDOT(&buffer, container.Registrations()))
+	require.Equal(t, `digraph wiring {
+	"mocks.Abstraction" [label="mocks.Abstraction\nsingleton"];
+	"pkg.ComplexAbstraction" [label="pkg.ComplexAbstraction\ntransient"];
+	"'token'" [label="'token'\nsingleton"];
+	"pkg.ComplexAbstraction" -> "mocks.Abstraction";
+}
+`, buffer.String())
+}
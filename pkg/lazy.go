@@ -0,0 +1,133 @@
+package pkg
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/4strodev/wiring/pkg/errors"
+)
+
+// Lazy is a dependency that is resolved the first time Get is called. Request it as a resolver
+// parameter or a struct field to postpone dependencies only needed on rare paths, or to break a
+// cycle between dependencies. Calling Get while the cycle is being built returns an error of
+// the [errors.ErrCircularDependency] kind.
+//
+//	container.Singleton(func(reports wiring.Lazy[*ReportService]) *Handler { ... })
+//
+// Resolvers can also request a provider function like func() (T, error), it resolves T every
+// time it is called so the lifecycle of T is honoured.
+type Lazy[T any] struct {
+	value *lazyValue
+}
+
+// lazyValue is shared by the copies of a [Lazy]
+type lazyValue struct {
+	once     sync.Once
+	resolve  func() (any, error)
+	instance any
+	err      error
+}
+
+// Get returns the dependency resolving it the first time. Following calls return the same result.
+func (l Lazy[T]) Get() (T, error) {
+	var value T
+	if l.value == nil {
+		return value, errors.Errorf("lazy %s was not created by a container", reflect.TypeFor[T]()).WithKind(errors.ErrNotRegistered).WithKey(typeKey(reflect.TypeFor[T]()))
+	}
+
+	l.value.once.Do(func() {
+		l.value.instance, l.value.err = l.value.resolve()
+	})
+	if l.value.err != nil {
+		return value, l.value.err
+	}
+	value, ok := l.value.instance.(T)
+	if !ok {
+		return value, errors.Errorf("wrong resolver for type %s", reflect.TypeFor[T]()).WithKind(errors.ErrWrongType).WithKey(typeKey(reflect.TypeFor[T]()))
+	}
+	return value, nil
+}
+
+func (Lazy[T]) target() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (Lazy[T]) withResolver(resolve func() (any, error)) any {
+	return Lazy[T]{value: &lazyValue{resolve: resolve}}
+}
+
+// deferred is implemented by [Lazy] so the container can create it through reflection
+type deferred interface {
+	target() reflect.Type
+	withResolver(resolve func() (any, error)) any
+}
+
+var (
+	deferredInterface = reflect.TypeFor[deferred]()
+	errorType         = reflect.TypeFor[error]()
+)
+
+// deferredTarget returns the type resolved by a [Lazy] or a provider function like func() (T, error)
+func deferredTarget(refType reflect.Type) (reflect.Type, bool) {
+	if refType.Implements(deferredInterface) {
+		return reflect.Zero(refType).Interface().(deferred).target(), true
+	}
+	if refType.Kind() == reflect.Func && refType.NumIn() == 0 && refType.NumOut() == 2 && refType.Out(1) == errorType {
+		return refType.Out(0), true
+	}
+	return nil, false
+}
+
+// newDeferred creates the Lazy or the provider function of the dependency. The dependency is
+// resolved from the container of the resolution, in a new resolution that keeps the values of
+// the context but not its cancellation because it can be resolved long after. While the resolver
+// that requested it is still running the new resolution continues its chain, so resolving a
+// dependency of the cycle reports it instead of waiting for the resolver forever.
+func newDeferred(dep dependency, res *resolution) any {
+	container := res.container
+	ctx := context.WithoutCancel(res.ctx)
+	target := dependency{refType: dep.refType, token: dep.token}
+	var chain []resolutionStep
+	var active *atomic.Bool
+	if len(res.steps) > 0 {
+		requester := &res.steps[len(res.steps)-1]
+		if requester.active == nil {
+			requester.active = new(atomic.Bool)
+			requester.active.Store(true)
+		}
+		active = requester.active
+		chain = slices.Clone(res.steps)
+		for i := range chain {
+			chain[i].active = nil
+		}
+	}
+	resolve := func() (any, error) {
+		if container.isClosed() {
+			return nil, ErrClosed
+		}
+		deferredRes := newResolution(ctx, container)
+		if active != nil && active.Load() {
+			deferredRes.steps = slices.Clone(chain)
+		}
+		return container.resolveDependency(target, deferredRes)
+	}
+
+	if dep.deferredType.Implements(deferredInterface) {
+		return reflect.Zero(dep.deferredType).Interface().(deferred).withResolver(resolve)
+	}
+	return reflect.MakeFunc(dep.deferredType, func([]reflect.Value) []reflect.Value {
+		value := reflect.New(dep.refType).Elem()
+		instance, err := resolve()
+		if err == nil && (instance == nil || !reflect.TypeOf(instance).AssignableTo(dep.refType)) {
+			err = errors.Errorf("wrong resolver for type %s", dep.refType).WithKind(errors.ErrWrongType).WithKey(dep.key())
+		}
+		if err != nil {
+			return []reflect.Value{value, reflect.ValueOf(&err).Elem()}
+		}
+		value.Set(reflect.ValueOf(instance))
+		return []reflect.Value{value, reflect.Zero(errorType)}
+	}).Interface()
+}
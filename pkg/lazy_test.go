@@ -0,0 +1,221 @@
+package pkg
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	wiringErrors "github.com/4strodev/wiring/pkg/errors"
+	"github.com/4strodev/wiring/pkg/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+type lazyService struct {
+	abstraction Lazy[mocks.Abstraction]
+}
+
+type lazyA struct {
+	b Lazy[*lazyB]
+}
+
+type lazyB struct {
+	a *lazyA
+}
+
+type counter struct {
+	value int
+}
+
+func TestLazy(t *testing.T) {
+	t.Run("should resolve the dependency on the first Get", func(t *testing.T) {
+		container := New()
+		calls := 0
+		err := container.Singleton(func() mocks.Abstraction {
+			calls++
+			return mocks.Resolver()
+		})
+		require.NoError(t, err)
+		err = container.Singleton(func(abstraction Lazy[mocks.Abstraction]) *lazyService {
+			return &lazyService{abstraction: abstraction}
+		})
+		require.NoError(t, err)
+
+		service, err := Get[*lazyService](container)
+		require.NoError(t, err)
+		require.Equal(t, 0, calls)
+
+		first, err := service.abstraction.Get()
+		require.NoError(t, err)
+		second, err := service.abstraction.Get()
+		require.NoError(t, err)
+		require.Same(t, first, second)
+		require.Equal(t, 1, calls)
+	})
+	t.Run("should break cycles", func(t *testing.T) {
+		for _, build := range []bool{false, true} {
+			container := New()
+			err := container.Singleton(func(b Lazy[*lazyB]) *lazyA {
+				return &lazyA{b: b}
+			})
+			require.NoError(t, err)
+			err = container.Singleton(func(a *lazyA) *lazyB {
+				return &lazyB{a: a}
+			})
+			require.NoError(t, err)
+			require.NoError(t, container.Validate())
+			if build {
+				require.NoError(t, container.Build())
+			}
+
+			a, err := Get[*lazyA](container)
+			require.NoError(t, err)
+			b, err := a.b.Get()
+			require.NoError(t, err)
+			require.Same(t, a, b.a)
+		}
+	})
+	t.Run("should report cycles used while they are built", func(t *testing.T) {
+		for _, build := range []bool{false, true} {
+			container := New()
+			err := container.Singleton(func(b Lazy[*lazyB]) (*lazyA, error) {
+				_, err := b.Get()
+				return &lazyA{b: b}, err
+			})
+			require.NoError(t, err)
+			err = container.Singleton(func(a *lazyA) *lazyB {
+				return &lazyB{a: a}
+			})
+			require.NoError(t, err)
+			err = container.Singleton(func(a func() (*lazyA, error)) (*counter, error) {
+				_, err := a()
+				return &counter{}, err
+			})
+			require.NoError(t, err)
+			if build {
+				require.NoError(t, container.Build())
+			}
+
+			done := make(chan error, 2)
+			go func() {
+				_, err := Get[*lazyA](container)
+				done <- err
+				_, err = Get[*counter](container)
+				done <- err
+			}()
+			for range 2 {
+				select {
+				case err := <-done:
+					require.ErrorIs(t, err, wiringErrors.ErrCircularDependency)
+				case <-time.After(time.Second):
+					t.Fatal("resolution is deadlocked")
+				}
+			}
+		}
+	})
+	t.Run("should report missing dependencies", func(t *testing.T) {
+		container := New()
+		err := container.Singleton(func(abstraction Lazy[mocks.Abstraction]) *lazyService {
+			return &lazyService{abstraction: abstraction}
+		})
+		require.NoError(t, err)
+		require.EqualError(t, container.Validate(), "resolver for *pkg.lazyService requires type 'mocks.Abstraction' which is not set")
+
+		service, err := Get[*lazyService](container)
+		require.NoError(t, err)
+		_, err = service.abstraction.Get()
+		require.ErrorIs(t, err, wiringErrors.ErrNotRegistered)
+
+		var lazy Lazy[mocks.Abstraction]
+		_, err = lazy.Get()
+		require.ErrorIs(t, err, wiringErrors.ErrNotRegistered)
+	})
+	t.Run("should fill lazy fields by type and token", func(t *testing.T) {
+		container := InitializeContainer(t)
+		var filled struct {
+			Abstraction Lazy[mocks.Abstraction]
+			Message     Lazy[string] `wire:"token"`
+		}
+		require.NoError(t, container.Fill(&filled))
+
+		abstraction, err := filled.Abstraction.Get()
+		require.NoError(t, err)
+		require.NotNil(t, abstraction)
+		message, err := filled.Message.Get()
+		require.NoError(t, err)
+		require.Equal(t, mocks.DEFAULT_MESSAGE, message)
+	})
+}
+
+func TestProvider(t *testing.T) {
+	t.Run("should honour the lifecycle of the dependency", func(t *testing.T) {
+		container := New()
+		err := container.Transient(func() *counter {
+			return &counter{}
+		})
+		require.NoError(t, err)
+		err = container.Singleton(mocks.Resolver)
+		require.NoError(t, err)
+
+		var newCounter func() (*counter, error)
+		var abstraction func() (mocks.Abstraction, error)
+		err = container.Singleton(func(counters func() (*counter, error), abstractions func() (mocks.Abstraction, error)) *lazyService {
+			newCounter, abstraction = counters, abstractions
+			return &lazyService{}
+		})
+		require.NoError(t, err)
+		require.NoError(t, container.Validate())
+		_, err = Get[*lazyService](container)
+		require.NoError(t, err)
+
+		first, err := newCounter()
+		require.NoError(t, err)
+		second, err := newCounter()
+		require.NoError(t, err)
+		require.NotSame(t, first, second)
+
+		firstAbstraction, err := abstraction()
+		require.NoError(t, err)
+		secondAbstraction, err := abstraction()
+		require.NoError(t, err)
+		require.Same(t, firstAbstraction, secondAbstraction)
+	})
+	t.Run("should resolve scoped dependencies from the scope", func(t *testing.T) {
+		container := New()
+		err := container.Scoped(func() *counter {
+			return &counter{}
+		})
+		require.NoError(t, err)
+		err = container.Transient(func(ctx context.Context, counters func() (*counter, error)) *lazyService {
+			first, err := counters()
+			require.NoError(t, err)
+			second, err := counters()
+			require.NoError(t, err)
+			require.Same(t, first, second)
+			return &lazyService{}
+		})
+		require.NoError(t, err)
+
+		_, err = Get[*lazyService](container.NewScope())
+		require.NoError(t, err)
+	})
+	t.Run("should use registered provider types", func(t *testing.T) {
+		container := New()
+		err := container.Singleton(func() func() (*counter, error) {
+			return func() (*counter, error) {
+				return &counter{value: 1}, nil
+			}
+		})
+		require.NoError(t, err)
+		err = container.Transient(func(counters func() (*counter, error)) *counter {
+			registered, err := counters()
+			require.NoError(t, err)
+			return &counter{value: registered.value + 1}
+		})
+		require.NoError(t, err)
+		require.NoError(t, container.Validate())
+
+		resolved, err := Get[*counter](container)
+		require.NoError(t, err)
+		require.Equal(t, 2, resolved.value)
+	})
+}
@@ -0,0 +1,346 @@
+// Package lifecycle starts and stops the components of an application wired by a container.
+//
+//	lc := lifecycle.New(lifecycle.WithStartTimeout(10 * time.Second))
+//	container := wiring.New(wiring.WithHook(lc))
+//	lc.Register(container)
+//
+//	container.Singleton(func(lc *lifecycle.Lifecycle, handler http.Handler) *http.Server {
+//		server := &http.Server{Handler: handler}
+//		lc.Append(lifecycle.Hook{
+//			OnStart: func(ctx context.Context) error { go server.ListenAndServe(); return nil },
+//			OnStop:  server.Shutdown,
+//		})
+//		return server
+//	})
+package lifecycle
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/4strodev/wiring/pkg"
+	"github.com/4strodev/wiring/pkg/errors"
+)
+
+// ErrStarted is returned when starting a lifecycle that has already been started
+var ErrStarted = errors.NewError("lifecycle already started")
+
+// Starter is implemented by singletons that have to be started with the application
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// Stopper is implemented by singletons that have to be stopped with the application
+type Stopper interface {
+	Stop(ctx context.Context) error
+}
+
+// Hook is a pair of functions run when the application starts and stops, any of them can be nil
+type Hook struct {
+	// Name identifies the hook on errors
+	Name    string
+	OnStart func(ctx context.Context) error
+	OnStop  func(ctx context.Context) error
+}
+
+// Option configures a [Lifecycle]
+type Option func(*Lifecycle)
+
+// WithStartTimeout limits the time every start hook can take, by default there is no limit
+func WithStartTimeout(timeout time.Duration) Option {
+	return func(l *Lifecycle) {
+		l.startTimeout = timeout
+	}
+}
+
+// WithStopTimeout limits the time every stop hook can take, by default there is no limit
+func WithStopTimeout(timeout time.Duration) Option {
+	return func(l *Lifecycle) {
+		l.stopTimeout = timeout
+	}
+}
+
+// Lifecycle collects the hooks of the components of an application in dependency order. Hooks are
+// appended by resolvers with Append and for singletons implementing [Starter] or [Stopper] when they
+// are created, the lifecycle must be added to the container with [pkg.WithHook] for the latter.
+// Since dependencies are created before their dependents, Start runs the hooks in dependency order
+// and Stop in reverse order.
+type Lifecycle struct {
+	startTimeout time.Duration
+	stopTimeout  time.Duration
+
+	mutex   sync.Mutex
+	entries []*entry
+	running bool
+	// problems are the errors of the singletons restarted once they were rebuilt, they are
+	// returned by the next Stop
+	problems []error
+	// restarts are the replacements being started in the background
+	restarts sync.WaitGroup
+}
+
+// entry is a hook of the lifecycle
+type entry struct {
+	Hook
+	// key and instance identify the singleton the hook belongs to, they are empty for appended hooks
+	key      string
+	instance any
+	started  bool
+	// retired is set when the instance of the singleton is retired before its replacement is
+	// created, restart reports if the replacement has to be started once it is created
+	retired bool
+	restart bool
+}
+
+// New creates a lifecycle configured with the options
+func New(options ...Option) *Lifecycle {
+	l := &Lifecycle{}
+	for _, option := range options {
+		option(l)
+	}
+	return l
+}
+
+// Register registers the lifecycle as a singleton so resolvers can depend on it to append hooks
+func (l *Lifecycle) Register(container pkg.Container) error {
+	return container.Singleton(func() *Lifecycle { return l })
+}
+
+// Append adds a hook, hooks appended while the lifecycle is starting are started as well
+func (l *Lifecycle) Append(hook Hook) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if hook.Name == "" {
+		hook.Name = fmt.Sprintf("hook %d", len(l.entries))
+	}
+	l.entries = append(l.entries, &entry{Hook: hook})
+}
+
+// BeforeResolve implements pkg.Hook.
+func (l *Lifecycle) BeforeResolve(event pkg.ResolveEvent) {}
+
+// AfterResolve implements pkg.Hook.
+func (l *Lifecycle) AfterResolve(event pkg.ResolveEvent) {}
+
+// ResolveFailed implements pkg.Hook.
+func (l *Lifecycle) ResolveFailed(event pkg.ResolveEvent) {}
+
+// InstanceCreated implements pkg.Hook. It adds the hooks of singletons implementing [Starter] or
+// [Stopper]. When a started singleton is rebuilt the hook of the new instance is only recorded,
+// it is started once the previous instance is retired, see InstanceRetired.
+func (l *Lifecycle) InstanceCreated(event pkg.ResolveEvent) {
+	// The lifecycle implements Starter and Stopper itself when it is registered
+	if event.LifeCycle != pkg.SINGLETON || event.Instance == l {
+		return
+	}
+	replacement := &entry{Hook: Hook{Name: event.Key}, key: event.Key, instance: event.Instance}
+	if starter, ok := event.Instance.(Starter); ok {
+		replacement.OnStart = starter.Start
+	}
+	if stopper, ok := event.Instance.(Stopper); ok {
+		replacement.OnStop = stopper.Stop
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	index := l.last(event.Key)
+	switch {
+	case index < 0:
+		l.entries = append(l.entries, replacement)
+	case l.entries[index].started:
+		// The previous instance is still in use, the replacement waits until it is retired
+		l.entries = slices.Insert(l.entries, index+1, replacement)
+	default:
+		previous := l.entries[index]
+		l.entries[index] = replacement
+		if previous.retired && previous.restart && l.running {
+			// The resolution that created the instance has not cached it yet
+			l.restarts.Add(1)
+			go func() {
+				defer l.restarts.Done()
+				l.record(l.start(context.Background(), replacement))
+			}()
+		}
+	}
+}
+
+// InstanceRetired implements pkg.RetireHook. The retired instance is stopped if it was started
+// and its replacement is started after it, so they never run at the same time.
+func (l *Lifecycle) InstanceRetired(event pkg.ResolveEvent) {
+	l.mutex.Lock()
+	index := slices.IndexFunc(l.entries, func(e *entry) bool {
+		return e.key == event.Key && sameInstance(e.instance, event.Instance)
+	})
+	if index < 0 {
+		l.mutex.Unlock()
+		return
+	}
+	previous := l.entries[index]
+	started := previous.started
+	previous.started = false
+	var replacement *entry
+	if next := l.last(event.Key); next > index {
+		replacement = l.entries[next]
+		l.entries = slices.Delete(l.entries, index, index+1)
+	} else {
+		l.entries[index] = &entry{key: event.Key, retired: true, restart: started}
+	}
+	running := l.running
+	l.mutex.Unlock()
+
+	if !started {
+		return
+	}
+	if previous.OnStop != nil {
+		err := run(event.Context, l.stopTimeout, previous.OnStop)
+		if err != nil {
+			l.record(errors.Errorf("error stopping %s: %w", previous.Name, err))
+		}
+	}
+	if replacement != nil && running {
+		l.record(l.start(event.Context, replacement))
+	}
+}
+
+// last returns the index of the last entry of the singleton, -1 if there is none
+func (l *Lifecycle) last(key string) int {
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		if l.entries[i].key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// record keeps the error of a restart so it is returned by the next Stop
+func (l *Lifecycle) record(err error) {
+	if err == nil {
+		return
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.problems = append(l.problems, err)
+}
+
+// Start runs the start hooks in the order they were appended. If a hook fails the hooks already
+// started are stopped in reverse order and the errors are returned.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	l.mutex.Lock()
+	if l.running {
+		l.mutex.Unlock()
+		return ErrStarted
+	}
+	l.running = true
+	l.mutex.Unlock()
+
+	for {
+		l.mutex.Lock()
+		index := slices.IndexFunc(l.entries, func(e *entry) bool {
+			return !e.started && !e.retired && !l.waiting(e)
+		})
+		if index < 0 {
+			l.mutex.Unlock()
+			return nil
+		}
+		pending := l.entries[index]
+		l.mutex.Unlock()
+
+		err := l.start(ctx, pending)
+		if err != nil {
+			return errors.WrapError(stderrors.Join(err, l.Stop(ctx)))
+		}
+	}
+}
+
+// waiting reports if the entry is the replacement of a started instance not retired yet
+func (l *Lifecycle) waiting(e *entry) bool {
+	if e.key == "" {
+		return false
+	}
+	return slices.ContainsFunc(l.entries, func(other *entry) bool {
+		return other != e && other.key == e.key && other.started
+	})
+}
+
+// start runs the start hook of the entry and marks it as started
+func (l *Lifecycle) start(ctx context.Context, pending *entry) error {
+	if pending.OnStart != nil {
+		err := run(ctx, l.startTimeout, pending.OnStart)
+		if err != nil {
+			return errors.Errorf("error starting %s: %w", pending.Name, err)
+		}
+	}
+	l.mutex.Lock()
+	pending.started = true
+	l.mutex.Unlock()
+	return nil
+}
+
+// Stop runs the stop hooks of the started hooks in reverse order. Every hook is stopped
+// even if some of them fail, the errors are aggregated with the errors of the singletons
+// restarted while the lifecycle was running.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	l.restarts.Wait()
+	l.mutex.Lock()
+	var started []*entry
+	for _, e := range l.entries {
+		if e.started {
+			started = append(started, e)
+			e.started = false
+		}
+	}
+	problems := l.problems
+	l.problems = nil
+	l.running = false
+	l.mutex.Unlock()
+
+	for i := len(started) - 1; i >= 0; i-- {
+		if started[i].OnStop == nil {
+			continue
+		}
+		err := run(ctx, l.stopTimeout, started[i].OnStop)
+		if err != nil {
+			problems = append(problems, errors.Errorf("error stopping %s: %w", started[i].Name, err))
+		}
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.WrapError(stderrors.Join(problems...))
+}
+
+// sameInstance reports if both instances are the same, instances of types that cannot be
+// compared are never the same
+func sameInstance(a any, b any) bool {
+	if a == nil || b == nil || !reflect.TypeOf(a).Comparable() || !reflect.TypeOf(b).Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// run executes the hook with the timeout. The hook is abandoned if it does not return once
+// the context is done.
+func run(ctx context.Context, timeout time.Duration, hook func(ctx context.Context) error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- hook(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,271 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/4strodev/wiring/pkg"
+	"github.com/4strodev/wiring/pkg/lifecycle"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder collects the events of the components, they can be started and stopped in the background
+type recorder struct {
+	mutex  sync.Mutex
+	events []string
+}
+
+func (r *recorder) record(event string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) list() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return slices.Clone(r.events)
+}
+
+// component records when it is started and stopped
+type component struct {
+	name     string
+	events   *recorder
+	startErr error
+	// onStart is called when the component is started
+	onStart func() error
+}
+
+func (c *component) Start(ctx context.Context) error {
+	c.events.record("start " + c.name)
+	if c.onStart != nil {
+		return c.onStart()
+	}
+	return c.startErr
+}
+
+func (c *component) Stop(ctx context.Context) error {
+	c.events.record("stop " + c.name)
+	return nil
+}
+
+type database struct{ *component }
+
+type server struct{ *component }
+
+func TestLifecycle(t *testing.T) {
+	t.Run("should run the starters and stoppers in dependency order", func(t *testing.T) {
+		events := &recorder{}
+		lc := lifecycle.New()
+		container := pkg.New(pkg.WithHook(lc))
+		require.NoError(t, container.Singleton(func(db database) server {
+			return server{&component{name: "server", events: events}}
+		}))
+		require.NoError(t, container.Singleton(func() database {
+			return database{&component{name: "database", events: events}}
+		}))
+
+		_, err := pkg.Get[server](container)
+		require.NoError(t, err)
+		require.NoError(t, lc.Start(context.Background()))
+		require.ErrorIs(t, lc.Start(context.Background()), lifecycle.ErrStarted)
+		require.NoError(t, lc.Stop(context.Background()))
+		require.Equal(t, []string{"start database", "start server", "stop server", "stop database"}, events.list())
+	})
+
+	t.Run("should restart rebuilt singletons once the previous instance is retired", func(t *testing.T) {
+		events := &recorder{}
+		lc := lifecycle.New()
+		container := pkg.New(pkg.WithHook(lc))
+		generation := 0
+		require.NoError(t, container.Singleton(func() database {
+			generation++
+			return database{&component{name: fmt.Sprintf("database %d", generation), events: events}}
+		}))
+
+		_, err := pkg.Get[database](container)
+		require.NoError(t, err)
+		require.NoError(t, lc.Start(context.Background()))
+		require.NoError(t, container.Invalidate(reflect.TypeFor[database]()))
+		_, err = pkg.Get[database](container)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return len(events.list()) == 3 }, time.Second, time.Millisecond)
+		require.NoError(t, lc.Stop(context.Background()))
+		require.Equal(t, []string{"start database 1", "stop database 1", "start database 2", "stop database 2"}, events.list())
+	})
+
+	t.Run("should keep the previous instance running during the grace period", func(t *testing.T) {
+		events := &recorder{}
+		lc := lifecycle.New()
+		container := pkg.New(pkg.WithHook(lc), pkg.WithGracePeriod(time.Hour))
+		generation := 0
+		require.NoError(t, container.Singleton(func() database {
+			generation++
+			return database{&component{name: fmt.Sprintf("database %d", generation), events: events}}
+		}))
+
+		_, err := pkg.Get[database](container)
+		require.NoError(t, err)
+		require.NoError(t, lc.Start(context.Background()))
+		require.NoError(t, container.Invalidate(reflect.TypeFor[database]()))
+		_, err = pkg.Get[database](container)
+		require.NoError(t, err)
+		require.Never(t, func() bool { return len(events.list()) > 1 }, 50*time.Millisecond, time.Millisecond)
+
+		// The replacement is not started before the previous instance is retired
+		require.NoError(t, lc.Stop(context.Background()))
+		require.Equal(t, []string{"start database 1", "stop database 1"}, events.list())
+	})
+
+	t.Run("should let restarted singletons resolve their dependents", func(t *testing.T) {
+		events := &recorder{}
+		lc := lifecycle.New()
+		container := pkg.New(pkg.WithHook(lc))
+		generation := 0
+		require.NoError(t, container.Singleton(func() database {
+			generation++
+			db := database{&component{name: fmt.Sprintf("database %d", generation), events: events}}
+			db.onStart = func() error {
+				_, err := pkg.Get[server](container)
+				return err
+			}
+			return db
+		}))
+		require.NoError(t, container.Singleton(func(db database) server {
+			return server{&component{name: "server", events: events}}
+		}, pkg.FollowRefresh()))
+
+		_, err := pkg.Get[server](container)
+		require.NoError(t, err)
+		require.NoError(t, lc.Start(context.Background()))
+		require.NoError(t, container.Invalidate(reflect.TypeFor[database]()))
+
+		resolved := make(chan error, 1)
+		go func() {
+			_, err := pkg.Get[server](container)
+			resolved <- err
+		}()
+		select {
+		case err := <-resolved:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("resolution is deadlocked")
+		}
+		require.Eventually(t, func() bool { return slices.Contains(events.list(), "start database 2") }, time.Second, time.Millisecond)
+		require.NoError(t, lc.Stop(context.Background()))
+	})
+
+	t.Run("should start swapped singletons once they are created", func(t *testing.T) {
+		events := &recorder{}
+		lc := lifecycle.New()
+		container := pkg.New(pkg.WithHook(lc))
+		require.NoError(t, container.Singleton(func() database {
+			return database{&component{name: "database 1", events: events}}
+		}))
+
+		_, err := pkg.Get[database](container)
+		require.NoError(t, err)
+		require.NoError(t, lc.Start(context.Background()))
+		require.NoError(t, container.Swap(func() database {
+			return database{&component{name: "database 2", events: events}}
+		}))
+		require.Eventually(t, func() bool { return len(events.list()) == 2 }, time.Second, time.Millisecond)
+		_, err = pkg.Get[database](container)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return len(events.list()) == 3 }, time.Second, time.Millisecond)
+		require.NoError(t, lc.Stop(context.Background()))
+		require.Equal(t, []string{"start database 1", "stop database 1", "start database 2", "stop database 2"}, events.list())
+	})
+
+	t.Run("should run the hooks appended by resolvers", func(t *testing.T) {
+		var events []string
+		lc := lifecycle.New()
+		container := pkg.New(pkg.WithHook(lc))
+		require.NoError(t, lc.Register(container))
+		require.NoError(t, container.Singleton(func(lc *lifecycle.Lifecycle) *time.Ticker {
+			ticker := time.NewTicker(time.Hour)
+			lc.Append(lifecycle.Hook{
+				Name: "ticker",
+				OnStop: func(ctx context.Context) error {
+					events = append(events, "stop ticker")
+					ticker.Stop()
+					return nil
+				},
+			})
+			return ticker
+		}))
+
+		_, err := pkg.Get[*time.Ticker](container)
+		require.NoError(t, err)
+		require.NoError(t, lc.Start(context.Background()))
+		require.NoError(t, lc.Stop(context.Background()))
+		require.Equal(t, []string{"stop ticker"}, events)
+	})
+
+	t.Run("should roll back the started hooks when a start fails", func(t *testing.T) {
+		events := &recorder{}
+		lc := lifecycle.New()
+		container := pkg.New(pkg.WithHook(lc))
+		require.NoError(t, container.Singleton(func(db database) server {
+			return server{&component{name: "server", events: events, startErr: errors.New("address in use")}}
+		}))
+		require.NoError(t, container.Singleton(func() database {
+			return database{&component{name: "database", events: events}}
+		}))
+
+		_, err := pkg.Get[server](container)
+		require.NoError(t, err)
+		err = lc.Start(context.Background())
+		require.ErrorContains(t, err, "error starting lifecycle_test.server: address in use")
+		require.Equal(t, []string{"start database", "start server", "stop database"}, events.list())
+
+		// Nothing is left to stop
+		require.NoError(t, lc.Stop(context.Background()))
+		require.Len(t, events.list(), 3)
+	})
+
+	t.Run("should limit the hooks with the timeouts", func(t *testing.T) {
+		lc := lifecycle.New(lifecycle.WithStartTimeout(10*time.Millisecond), lifecycle.WithStopTimeout(10*time.Millisecond))
+		stopped := false
+		lc.Append(lifecycle.Hook{
+			Name: "first",
+			OnStop: func(ctx context.Context) error {
+				stopped = true
+				return nil
+			},
+		})
+		lc.Append(lifecycle.Hook{
+			Name: "slow",
+			OnStart: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		})
+
+		err := lc.Start(context.Background())
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.ErrorContains(t, err, "error starting slow")
+		require.True(t, stopped)
+	})
+
+	t.Run("should aggregate the stop errors", func(t *testing.T) {
+		lc := lifecycle.New()
+		for _, name := range []string{"first", "second"} {
+			lc.Append(lifecycle.Hook{
+				Name:   name,
+				OnStop: func(ctx context.Context) error { return errors.New(name + " failed") },
+			})
+		}
+
+		require.NoError(t, lc.Start(context.Background()))
+		err := lc.Stop(context.Background())
+		require.ErrorContains(t, err, "error stopping second: second failed")
+		require.ErrorContains(t, err, "error stopping first: first failed")
+	})
+}
@@ -0,0 +1,207 @@
+package pkg
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/4strodev/wiring/pkg/errors"
+)
+
+// RefreshPolicy defines when the instance of a singleton is rebuilt
+type RefreshPolicy struct {
+	// TTL is the time the instance is cached, it is rebuilt once it expires. There is no
+	// limit when it is zero, the instance is only rebuilt when it is invalidated.
+	TTL time.Duration
+	// Background keeps serving the previous instance while the new one is built. Otherwise
+	// the resolution that finds the instance expired or invalidated waits for the new one.
+	Background bool
+}
+
+// Refresh sets the refresh policy of a singleton. Previous instances are closed like the
+// container does on Close once they are replaced and the grace period is over.
+//
+//	container.Singleton(NewCredentials, wiring.Refresh(wiring.RefreshPolicy{TTL: time.Hour, Background: true}))
+func Refresh(policy RefreshPolicy) RegistrationOption {
+	return func(registration *registrationOptions) {
+		registration.refresh = &policy
+	}
+}
+
+// FollowRefresh rebuilds a singleton when any of its singleton dependencies is refreshed, so
+// it never keeps a previous instance of them
+func FollowRefresh() RegistrationOption {
+	return func(registration *registrationOptions) {
+		registration.followRefresh = true
+	}
+}
+
+// Invalidate implements pkg.Container.
+func (w *wireContainer) Invalidate(refType reflect.Type) error {
+	spec, err := w.snapshot().getSpec(refType)
+	if err != nil {
+		return err
+	}
+	return w.invalidate(typeKey(refType), spec)
+}
+
+// InvalidateToken implements pkg.Container.
+func (w *wireContainer) InvalidateToken(token string) error {
+	spec, err := w.snapshot().getSpecForToken(token)
+	if err != nil {
+		return err
+	}
+	return w.invalidate(tokenKey(token), spec)
+}
+
+func (w *wireContainer) invalidate(key string, spec *dependencySpec) error {
+	if w.isClosed() {
+		return ErrClosed
+	}
+	if spec.lifeCycle != SINGLETON {
+		return errors.Errorf("%s cannot be invalidated, only singletons can", key).WithKind(errors.ErrInvalidResolver).WithKey(key)
+	}
+
+	spec.invalidated.Store(true)
+	if spec.refresh != nil && spec.refresh.Background && spec.instantiated.Load() {
+		spec.refreshInBackground(context.Background(), key)
+	}
+	return nil
+}
+
+// stale reports if the cached instance has to be rebuilt because it was invalidated, it expired
+// or, when it follows the refreshes, any of its dependencies has been refreshed
+func (spec *dependencySpec) stale() bool {
+	if spec.invalidated.Load() {
+		return true
+	}
+	expires := spec.expires.Load()
+	if expires != 0 && !spec.container.clock.Now().Before(time.Unix(0, expires)) {
+		return true
+	}
+	if !spec.followRefresh {
+		return false
+	}
+
+	versions := spec.dependencyVersions.Load()
+	if versions == nil {
+		return false
+	}
+	for dependency, version := range *versions {
+		if dependency.version.Load() != version || dependency.stale() {
+			return true
+		}
+	}
+	return false
+}
+
+// cache caches the singleton instance replacing the previous one, it must be called
+// holding the mutex of the spec. It returns false without caching the instance if the
+// container has been closed.
+func (spec *dependencySpec) cache(res *resolution, key string, instance any) bool {
+	if !spec.container.instantiated(spec, key, instance) {
+		return false
+	}
+	spec.instance = instance
+	spec.decorations = nil
+	spec.instantiated.Store(true)
+	spec.invalidated.Store(false)
+
+	if spec.refresh != nil && spec.refresh.TTL > 0 {
+		spec.expires.Store(spec.container.clock.Now().Add(spec.refresh.TTL).UnixNano())
+	}
+	if spec.followRefresh {
+		versions := make(map[*dependencySpec]uint64)
+		for _, dep := range spec.dependencies(res.registry) {
+			for _, bound := range res.registry.boundSpecs(dep) {
+				if bound.spec.lifeCycle == SINGLETON {
+					versions[bound.spec] = bound.spec.version.Load()
+				}
+			}
+		}
+		spec.dependencyVersions.Store(&versions)
+	}
+	spec.version.Add(1)
+	return true
+}
+
+// refreshInBackground rebuilds the instance without blocking the resolutions, which keep
+// receiving the previous instance until the new one is cached. Failed rebuilds are tried
+// again on the next resolution.
+func (spec *dependencySpec) refreshInBackground(ctx context.Context, key string) {
+	if !spec.refreshing.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer spec.refreshing.Store(false)
+		if spec.container.isClosed() {
+			return
+		}
+
+		res := newResolution(context.WithoutCancel(ctx), spec.container)
+		err := res.enter(key, spec)
+		if err != nil {
+			return
+		}
+		defer res.leave()
+		instance, err := spec.create(res, key)
+		if err != nil || instance == nil {
+			return
+		}
+
+		spec.mutex.Lock()
+		previous := spec.instance
+		cached := spec.cache(res, key, instance)
+		spec.mutex.Unlock()
+		if !cached {
+			// Close already handled the previous instance
+			spec.container.closeDiscarded(spec, key, instance)
+			return
+		}
+		spec.container.retire(spec, key, previous)
+	}()
+}
+
+// retire retires an instance of the spec that is no longer cached by the container once the
+// grace period is over, so the resolutions that already received it can finish
+func (w *wireContainer) retire(spec *dependencySpec, key string, instance any) {
+	if instance == nil || spec.unmanaged {
+		return
+	}
+	w.afterGracePeriod(func() {
+		w.retired(spec, key, instance)
+	})
+}
+
+// retired notifies the retire hooks and closes the retired instance
+func (w *wireContainer) retired(spec *dependencySpec, key string, instance any) {
+	if instance == nil || spec.unmanaged {
+		return
+	}
+	w.hooks.instanceRetired(ResolveEvent{
+		Context:   context.Background(),
+		Key:       key,
+		LifeCycle: spec.lifeCycle,
+		Instance:  instance,
+	})
+	w.closeDiscarded(spec, key, instance)
+}
+
+// closeDiscarded closes an instance of the spec that is no longer cached, or that was created once the
+// container was closed, reporting the error to the ResolveFailed hooks
+func (w *wireContainer) closeDiscarded(spec *dependencySpec, key string, instance any) {
+	if instance == nil || spec.unmanaged {
+		return
+	}
+	ctx := context.Background()
+	err := closeInstance(ctx, instance)
+	if err != nil {
+		w.hooks.resolveFailed(ResolveEvent{
+			Context:   ctx,
+			Key:       key,
+			LifeCycle: spec.lifeCycle,
+			Err:       errors.Errorf("error closing discarded instance of %s: %w", key, err).WithKey(key),
+		})
+	}
+}
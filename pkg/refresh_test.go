@@ -0,0 +1,249 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	wiringErrors "github.com/4strodev/wiring/pkg/errors"
+	"github.com/4strodev/wiring/pkg/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// credentials is closed once it is replaced
+type credentials struct {
+	version int
+	closed  atomic.Bool
+	closes  atomic.Int32
+}
+
+func (c *credentials) Close() error {
+	c.closed.Store(true)
+	c.closes.Add(1)
+	return nil
+}
+
+var errUnclosable = errors.New("unclosable")
+
+// unclosable fails when it is closed
+type unclosable struct{}
+
+func (unclosable) Close() error {
+	return errUnclosable
+}
+
+type credentialsClient struct {
+	credentials *credentials
+}
+
+// credentialsResolver returns a new version of the credentials on every call
+func credentialsResolver(versions *atomic.Int32) func() *credentials {
+	return func() *credentials {
+		return &credentials{version: int(versions.Add(1))}
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	credentialsType := reflect.TypeFor[*credentials]()
+
+	t.Run("should rebuild the instance once the TTL expires", func(t *testing.T) {
+		clock := newFakeClock()
+		container := New(WithClock(clock))
+		var versions atomic.Int32
+		require.NoError(t, container.Singleton(credentialsResolver(&versions), Refresh(RefreshPolicy{TTL: time.Minute})))
+
+		first, err := Get[*credentials](container)
+		require.NoError(t, err)
+		clock.Advance(59 * time.Second)
+		cached, err := Get[*credentials](container)
+		require.NoError(t, err)
+		require.Same(t, first, cached)
+
+		clock.Advance(time.Second)
+		second, err := Get[*credentials](container)
+		require.NoError(t, err)
+		require.Equal(t, 2, second.version)
+		require.Eventually(t, first.closed.Load, time.Second, time.Millisecond)
+
+		// Only the current instance is closed with the container
+		require.NoError(t, container.Close(context.Background()))
+		require.True(t, second.closed.Load())
+	})
+
+	t.Run("should rebuild the instance once it is invalidated", func(t *testing.T) {
+		container := New()
+		var versions atomic.Int32
+		require.NoError(t, container.Singleton(credentialsResolver(&versions)))
+		require.NoError(t, container.SingletonToken("client", func(c *credentials) *credentialsClient {
+			return &credentialsClient{credentials: c}
+		}))
+
+		first, err := Get[*credentials](container)
+		require.NoError(t, err)
+		require.NoError(t, container.Invalidate(credentialsType))
+		require.Equal(t, 1, first.version)
+
+		second, err := Get[*credentials](container)
+		require.NoError(t, err)
+		require.Equal(t, 2, second.version)
+		require.Eventually(t, first.closed.Load, time.Second, time.Millisecond)
+		require.False(t, second.closed.Load())
+
+		var client *credentialsClient
+		require.NoError(t, container.ResolveToken("client", &client))
+		require.NoError(t, container.InvalidateToken("client"))
+		var rebuilt *credentialsClient
+		require.NoError(t, container.ResolveToken("client", &rebuilt))
+		require.NotSame(t, client, rebuilt)
+		require.Same(t, second, rebuilt.credentials)
+	})
+
+	t.Run("should rebuild the instance in the background", func(t *testing.T) {
+		container := New()
+		var versions atomic.Int32
+		release := make(chan struct{})
+		require.NoError(t, container.Singleton(func() *credentials {
+			version := versions.Add(1)
+			if version > 1 {
+				<-release
+			}
+			return &credentials{version: int(version)}
+		}, Refresh(RefreshPolicy{Background: true})))
+
+		first, err := Get[*credentials](container)
+		require.NoError(t, err)
+		require.NoError(t, container.Invalidate(credentialsType))
+
+		// The previous instance is served while the new one is built
+		cached, err := Get[*credentials](container)
+		require.NoError(t, err)
+		require.Same(t, first, cached)
+
+		close(release)
+		require.Eventually(t, func() bool {
+			instance, err := Get[*credentials](container)
+			return err == nil && instance.version == 2
+		}, time.Second, time.Millisecond)
+		require.Eventually(t, first.closed.Load, time.Second, time.Millisecond)
+		require.EqualValues(t, 2, versions.Load())
+	})
+
+	t.Run("should rebuild the dependents following the refreshes", func(t *testing.T) {
+		clock := newFakeClock()
+		container := New(WithClock(clock))
+		var versions atomic.Int32
+		require.NoError(t, container.Singleton(credentialsResolver(&versions), Refresh(RefreshPolicy{TTL: time.Minute})))
+		require.NoError(t, container.Singleton(func(c *credentials) *credentialsClient {
+			return &credentialsClient{credentials: c}
+		}, FollowRefresh()))
+		require.NoError(t, container.Singleton(func(c *credentials) *lazyB { return &lazyB{} }))
+
+		client, err := Get[*credentialsClient](container)
+		require.NoError(t, err)
+		cached, err := Get[*credentialsClient](container)
+		require.NoError(t, err)
+		require.Same(t, client, cached)
+		other, err := Get[*lazyB](container)
+		require.NoError(t, err)
+
+		clock.Advance(time.Minute)
+		rebuilt, err := Get[*credentialsClient](container)
+		require.NoError(t, err)
+		require.NotSame(t, client, rebuilt)
+		require.Equal(t, 2, rebuilt.credentials.version)
+
+		// Dependents that do not follow the refreshes keep their instance
+		cached, err = Get[*credentialsClient](container)
+		require.NoError(t, err)
+		require.Same(t, rebuilt, cached)
+		otherCached, err := Get[*lazyB](container)
+		require.NoError(t, err)
+		require.Same(t, other, otherCached)
+	})
+
+	t.Run("should close the previous instance after the grace period", func(t *testing.T) {
+		failures := make(chan ResolveEvent, 1)
+		container := New(WithGracePeriod(20*time.Millisecond), WithHook(HookFuncs{
+			OnResolveFailed: func(event ResolveEvent) { failures <- event },
+		}))
+		var versions atomic.Int32
+		require.NoError(t, container.Singleton(func() (*credentials, error) {
+			return &credentials{version: int(versions.Add(1))}, nil
+		}))
+		require.NoError(t, container.SingletonToken("unclosable", func() io.Closer {
+			return unclosable{}
+		}))
+
+		first, err := Get[*credentials](container)
+		require.NoError(t, err)
+		require.NoError(t, container.Invalidate(credentialsType))
+		_, err = Get[*credentials](container)
+		require.NoError(t, err)
+		require.False(t, first.closed.Load())
+		require.Eventually(t, first.closed.Load, time.Second, time.Millisecond)
+
+		var closer io.Closer
+		require.NoError(t, container.ResolveToken("unclosable", &closer))
+		require.NoError(t, container.InvalidateToken("unclosable"))
+		require.NoError(t, container.ResolveToken("unclosable", &closer))
+		select {
+		case event := <-failures:
+			require.Equal(t, "'unclosable'", event.Key)
+			require.ErrorIs(t, event.Err, errUnclosable)
+		case <-time.After(time.Second):
+			t.Fatal("the close error was not reported")
+		}
+	})
+
+	t.Run("should close the instances rebuilt while the container is closed", func(t *testing.T) {
+		for _, background := range []bool{false, true} {
+			container := New()
+			var versions atomic.Int32
+			created := make(chan *credentials, 1)
+			release := make(chan struct{})
+			require.NoError(t, container.Singleton(func() *credentials {
+				instance := &credentials{version: int(versions.Add(1))}
+				if instance.version > 1 {
+					created <- instance
+					<-release
+				}
+				return instance
+			}, Refresh(RefreshPolicy{Background: background})))
+
+			first, err := Get[*credentials](container)
+			require.NoError(t, err)
+			require.NoError(t, container.Invalidate(credentialsType))
+			resolved := make(chan error, 1)
+			if !background {
+				go func() {
+					_, err := Get[*credentials](container)
+					resolved <- err
+				}()
+			}
+			second := <-created
+			require.NoError(t, container.Close(context.Background()))
+			close(release)
+
+			if !background {
+				require.ErrorIs(t, <-resolved, ErrClosed)
+			}
+			require.Eventually(t, second.closed.Load, time.Second, time.Millisecond)
+			require.EqualValues(t, 1, first.closes.Load())
+			require.EqualValues(t, 1, second.closes.Load())
+		}
+	})
+
+	t.Run("should only refresh singletons", func(t *testing.T) {
+		container := New()
+		require.ErrorIs(t, container.Transient(mocks.Resolver, Refresh(RefreshPolicy{TTL: time.Second})), wiringErrors.ErrInvalidResolver)
+		require.ErrorIs(t, container.Scoped(mocks.Resolver, FollowRefresh()), wiringErrors.ErrInvalidResolver)
+
+		require.NoError(t, container.Transient(mocks.Resolver))
+		require.ErrorIs(t, container.Invalidate(reflect.TypeFor[mocks.Abstraction]()), wiringErrors.ErrInvalidResolver)
+		require.ErrorIs(t, container.Invalidate(credentialsType), wiringErrors.ErrNotRegistered)
+	})
+}
@@ -0,0 +1,239 @@
+package pkg
+
+import (
+	"log"
+	"reflect"
+
+	"github.com/4strodev/wiring/pkg/errors"
+)
+
+// DuplicatePolicy defines what the container does when a dependency is registered twice
+type DuplicatePolicy uint8
+
+const (
+	// DUPLICATE_OVERRIDE replaces the previous registration silently
+	DUPLICATE_OVERRIDE DuplicatePolicy = iota
+	// DUPLICATE_WARN replaces the previous registration and reports the duplicate to the duplicate hook
+	DUPLICATE_WARN
+	// DUPLICATE_ERROR keeps the previous registration and returns an error
+	DUPLICATE_ERROR
+)
+
+// ErrDuplicateRegistration is returned when a dependency is registered twice with the [DUPLICATE_ERROR] policy
+var ErrDuplicateRegistration = errors.NewError("duplicate registration")
+
+// Option configures a container created with [New]
+type Option func(*wireContainer)
+
+// WithDuplicatePolicy sets the policy applied when a dependency is registered twice.
+// By default duplicated registrations override the previous ones. Use Replace or ReplaceToken
+// to override a dependency intentionally regardless of the policy.
+func WithDuplicatePolicy(policy DuplicatePolicy) Option {
+	return func(w *wireContainer) {
+		w.duplicatePolicy = policy
+	}
+}
+
+// WithDuplicateHook sets the function called with the duplicate error when the
+// [DUPLICATE_WARN] policy is used. By default duplicates are written to the standard logger.
+func WithDuplicateHook(hook func(err error)) Option {
+	return func(w *wireContainer) {
+		w.duplicateHook = hook
+	}
+}
+
+// RegistrationOption configures a single registration
+type RegistrationOption func(*registrationOptions)
+
+type registrationOptions struct {
+	// interfaces are the extra types the dependency is bound to
+	interfaces []reflect.Type
+	// eager singletons are instantiated by WarmUp
+	eager         bool
+	unmanaged     bool
+	retry         *RetryPolicy
+	refresh       *RefreshPolicy
+	followRefresh bool
+}
+
+func newRegistrationOptions(options []RegistrationOption) *registrationOptions {
+	registration := new(registrationOptions)
+	for _, option := range options {
+		option(registration)
+	}
+	return registration
+}
+
+// As binds the dependency to the interfaces besides its own type. Every interface resolves the
+// same spec, so singletons share the instance. The type returned by the resolver must implement
+// every interface.
+//
+//	container.Singleton(NewStore, wiring.As(reflect.TypeFor[Reader](), reflect.TypeFor[Writer]()))
+func As(interfaces ...reflect.Type) RegistrationOption {
+	return func(registration *registrationOptions) {
+		registration.interfaces = append(registration.interfaces, interfaces...)
+	}
+}
+
+// Unmanaged leaves the instances of the dependency open when the container is closed or when
+// they are replaced. Use it for instances owned by someone else.
+func Unmanaged() RegistrationOption {
+	return func(registration *registrationOptions) {
+		registration.unmanaged = true
+	}
+}
+
+// configure applies the registration to the spec and checks that the type of the spec implements
+// the interfaces it is bound to
+func (registration *registrationOptions) configure(spec *dependencySpec) error {
+	if registration.eager && spec.lifeCycle != SINGLETON {
+		return errors.Errorf("type '%s' cannot be eager, only singletons can", spec.Type()).WithKind(errors.ErrInvalidResolver)
+	}
+	if (registration.refresh != nil || registration.followRefresh) && spec.lifeCycle != SINGLETON {
+		return errors.Errorf("type '%s' cannot be refreshed, only singletons can", spec.Type()).WithKind(errors.ErrInvalidResolver)
+	}
+	spec.eager = registration.eager
+	spec.unmanaged = registration.unmanaged
+	spec.retry = registration.retry
+	spec.refresh = registration.refresh
+	spec.followRefresh = registration.followRefresh
+
+	for _, interfaceType := range registration.interfaces {
+		if interfaceType == nil || interfaceType.Kind() != reflect.Interface || !spec.Type().Implements(interfaceType) {
+			return errors.Errorf("type '%s' does not implement '%v'", spec.Type(), interfaceType).WithKind(errors.ErrInvalidResolver)
+		}
+	}
+	return nil
+}
+
+// bindInterfaces registers the spec under the interfaces of the registration
+func (w *wireContainer) bindInterfaces(r *registry, spec *dependencySpec, registration *registrationOptions) error {
+	for _, interfaceType := range registration.interfaces {
+		err := w.checkDuplicate(typeKey(interfaceType), r.typeMapping[interfaceType])
+		if err != nil {
+			return err
+		}
+		r.typeMapping[interfaceType] = spec
+	}
+	return nil
+}
+
+func defaultDuplicateHook(err error) {
+	log.Printf("wiring: %s", err)
+}
+
+// checkDuplicate applies the duplicate policy when the key has a previous registration
+func (w *wireContainer) checkDuplicate(key string, previous *dependencySpec) error {
+	if previous == nil {
+		return nil
+	}
+
+	err := errors.Errorf("%w: %s already registered at %s", ErrDuplicateRegistration, key, previous.source)
+	switch w.duplicatePolicy {
+	case DUPLICATE_ERROR:
+		return err
+	case DUPLICATE_WARN:
+		w.duplicateHook(err)
+	}
+	return nil
+}
+
+func (w *wireContainer) registerType(resolver any, lifeCycle abstractionLifeCycle, options []RegistrationOption) error {
+	spec, err := newSpec(resolver, lifeCycle, w)
+	if err != nil {
+		return err
+	}
+	return w.registerSpec(spec, newRegistrationOptions(options))
+}
+
+// registerSpec registers the spec under its type and the interfaces of the registration
+func (w *wireContainer) registerSpec(spec *dependencySpec, registration *registrationOptions) error {
+	err := registration.configure(spec)
+	if err != nil {
+		return err
+	}
+
+	return w.updateRegistry(func(r *registry) error {
+		err := w.checkDuplicate(typeKey(spec.Type()), r.typeMapping[spec.Type()])
+		if err != nil {
+			return err
+		}
+		r.typeMapping[spec.Type()] = spec
+		return w.bindInterfaces(r, spec, registration)
+	})
+}
+
+func (w *wireContainer) registerToken(token string, resolver any, lifeCycle abstractionLifeCycle, options []RegistrationOption) error {
+	spec, err := newSpec(resolver, lifeCycle, w)
+	if err != nil {
+		return err
+	}
+	registration := newRegistrationOptions(options)
+	err = registration.configure(spec)
+	if err != nil {
+		return err
+	}
+
+	return w.updateRegistry(func(r *registry) error {
+		err := w.checkDuplicate(tokenKey(token), r.tokenMapping[token])
+		if err != nil {
+			return err
+		}
+		r.tokenMapping[token] = spec
+		return w.bindInterfaces(r, spec, registration)
+	})
+}
+
+// Replace implements pkg.Container.
+func (w *wireContainer) Replace(resolver any) error {
+	spec, err := newSpec(resolver, SINGLETON, w)
+	if err != nil {
+		return err
+	}
+
+	return w.updateRegistry(func(r *registry) error {
+		previous, err := r.getSpec(spec.Type())
+		if err != nil {
+			return err
+		}
+		err = r.checkRebind(previous, spec)
+		if err != nil {
+			return err
+		}
+		spec.inherit(previous)
+		r.rebind(previous, spec)
+		return nil
+	})
+}
+
+// ReplaceToken implements pkg.Container.
+func (w *wireContainer) ReplaceToken(token string, resolver any) error {
+	spec, err := newSpec(resolver, SINGLETON, w)
+	if err != nil {
+		return err
+	}
+
+	return w.updateRegistry(func(r *registry) error {
+		previous, err := r.getSpecForToken(token)
+		if err != nil {
+			return err
+		}
+		err = r.checkRebind(previous, spec)
+		if err != nil {
+			return err
+		}
+		spec.inherit(previous)
+		r.rebind(previous, spec)
+		return nil
+	})
+}
+
+// inherit keeps the lifecycle and the registration options of the spec being replaced
+func (spec *dependencySpec) inherit(previous *dependencySpec) {
+	spec.lifeCycle = previous.lifeCycle
+	spec.eager = previous.eager
+	spec.unmanaged = previous.unmanaged
+	spec.retry = previous.retry
+	spec.refresh = previous.refresh
+	spec.followRefresh = previous.followRefresh
+}
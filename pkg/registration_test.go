@@ -0,0 +1,248 @@
+package pkg
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	wiringErrors "github.com/4strodev/wiring/pkg/errors"
+	"github.com/4strodev/wiring/pkg/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuplicatePolicy(t *testing.T) {
+	t.Run("should override duplicates by default", func(t *testing.T) {
+		container := InitializeContainer(t)
+		err := container.Singleton(mocks.ResolverWithMessage("overridden"))
+		require.NoError(t, err)
+
+		abstraction, err := Get[mocks.Abstraction](container)
+		require.NoError(t, err)
+		require.Equal(t, "overridden", abstraction.(*mocks.Implementation).Message)
+	})
+	t.Run("should return error with the original registration", func(t *testing.T) {
+		container := New(WithDuplicatePolicy(DUPLICATE_ERROR))
+		err := container.Singleton(mocks.Resolver)
+		require.NoError(t, err)
+		err = container.SingletonToken(mocks.TESTING_TOKEN, mocks.TokenResolver)
+		require.NoError(t, err)
+		err = container.SingletonGroup("greeter", mocks.Resolver)
+		require.NoError(t, err)
+
+		err = container.Transient(mocks.ResolverWithMessage("duplicate"))
+		require.ErrorIs(t, err, ErrDuplicateRegistration)
+		require.Regexp(t, `^duplicate registration: mocks.Abstraction already registered at .+registration_test.go:\d+$`, err.Error())
+		err = container.ScopedToken(mocks.TESTING_TOKEN, mocks.TokenResolver)
+		require.ErrorIs(t, err, ErrDuplicateRegistration)
+		require.ErrorContains(t, err, "'token' already registered at")
+		err = container.TransientGroup("greeter", mocks.Resolver)
+		require.ErrorIs(t, err, ErrDuplicateRegistration)
+
+		abstraction, err := Get[mocks.Abstraction](container)
+		require.NoError(t, err)
+		require.Equal(t, mocks.DEFAULT_MESSAGE, abstraction.(*mocks.Implementation).Message)
+	})
+	t.Run("should warn through the hook and override", func(t *testing.T) {
+		var warnings []error
+		container := New(WithDuplicatePolicy(DUPLICATE_WARN), WithDuplicateHook(func(err error) {
+			warnings = append(warnings, err)
+		}))
+		err := container.Singleton(mocks.Resolver)
+		require.NoError(t, err)
+		err = container.Singleton(mocks.ResolverWithMessage("overridden"))
+		require.NoError(t, err)
+
+		require.Len(t, warnings, 1)
+		require.ErrorIs(t, warnings[0], ErrDuplicateRegistration)
+		abstraction, err := Get[mocks.Abstraction](container)
+		require.NoError(t, err)
+		require.Equal(t, "overridden", abstraction.(*mocks.Implementation).Message)
+	})
+}
+
+func TestReplace(t *testing.T) {
+	t.Run("should replace keeping the lifecycle", func(t *testing.T) {
+		container := New(WithDuplicatePolicy(DUPLICATE_ERROR))
+		err := container.Transient(mocks.Resolver)
+		require.NoError(t, err)
+		err = container.SingletonToken(mocks.TESTING_TOKEN, mocks.TokenResolver)
+		require.NoError(t, err)
+
+		err = container.Replace(mocks.ResolverWithMessage("double"))
+		require.NoError(t, err)
+		err = container.ReplaceToken(mocks.TESTING_TOKEN, func() string {
+			return "double"
+		})
+		require.NoError(t, err)
+
+		first, err := Get[mocks.Abstraction](container)
+		require.NoError(t, err)
+		second, err := Get[mocks.Abstraction](container)
+		require.NoError(t, err)
+		require.Equal(t, "double", first.(*mocks.Implementation).Message)
+		require.NotSame(t, first, second)
+
+		message, err := GetToken[string](container, mocks.TESTING_TOKEN)
+		require.NoError(t, err)
+		require.Equal(t, "double", message)
+		require.Equal(t, SINGLETON, container.Registrations()[1].LifeCycle)
+	})
+	t.Run("should return error when there is nothing to replace", func(t *testing.T) {
+		container := New()
+		require.Error(t, container.Replace(mocks.Resolver))
+		require.Error(t, container.ReplaceToken(mocks.TESTING_TOKEN, mocks.TokenResolver))
+		require.ErrorIs(t, container.NewScope().Replace(mocks.Resolver), ErrScopeRegistration)
+	})
+}
+
+type reader interface {
+	Read() string
+}
+
+type writer interface {
+	Write(value string)
+}
+
+type store struct {
+	value string
+}
+
+func (s *store) Read() string {
+	return s.value
+}
+
+func (s *store) Write(value string) {
+	s.value = value
+}
+
+// readOnlyStore implements reader but cannot replace a *store
+type readOnlyStore struct{}
+
+func (readOnlyStore) Read() string {
+	return ""
+}
+
+func TestAs(t *testing.T) {
+	readerType := reflect.TypeFor[reader]()
+	writerType := reflect.TypeFor[writer]()
+
+	t.Run("should share the singleton between the interfaces", func(t *testing.T) {
+		container := New()
+		calls := 0
+		err := container.Singleton(func() *store {
+			calls++
+			return &store{}
+		}, As(readerType, writerType))
+		require.NoError(t, err)
+
+		w, err := Get[writer](container)
+		require.NoError(t, err)
+		w.Write("shared")
+		r, err := Get[reader](container)
+		require.NoError(t, err)
+		require.Equal(t, "shared", r.Read())
+		s, err := Get[*store](container)
+		require.NoError(t, err)
+		require.Same(t, s, r)
+		require.Equal(t, 1, calls)
+		require.NoError(t, container.Validate())
+	})
+	t.Run("should keep the interfaces bound when replaced", func(t *testing.T) {
+		container := New()
+		require.NoError(t, container.Singleton(func() *store { return &store{} }, As(readerType)))
+		require.NoError(t, container.SingletonToken("store", func() *store { return &store{} }, As(writerType)))
+
+		replacement := &store{}
+		require.NoError(t, container.Replace(func() *store { return replacement }))
+		r, err := Get[reader](container)
+		require.NoError(t, err)
+		require.Same(t, replacement, r)
+		s, err := Get[*store](container)
+		require.NoError(t, err)
+		require.Same(t, replacement, s)
+
+		tokenReplacement := &store{}
+		require.NoError(t, container.ReplaceToken("store", func() *store { return tokenReplacement }))
+		w, err := Get[writer](container)
+		require.NoError(t, err)
+		require.Same(t, tokenReplacement, w)
+		var token *store
+		require.NoError(t, container.ResolveToken("store", &token))
+		require.Same(t, tokenReplacement, token)
+	})
+	t.Run("should reject replacements that do not fit the bound types", func(t *testing.T) {
+		container := New()
+		require.NoError(t, container.Singleton(func() *store { return &store{} }, As(readerType)))
+		require.NoError(t, container.Transient(func(s *store) *repository { return &repository{} }))
+		require.NoError(t, container.SingletonToken("store", func() *store { return &store{} }, As(writerType)))
+
+		err := container.Replace(func() reader { return readOnlyStore{} })
+		require.ErrorIs(t, err, wiringErrors.ErrWrongType)
+		require.EqualError(t, err, "type 'pkg.reader' cannot replace the resolver of '*pkg.store'")
+		err = container.ReplaceToken("store", func() reader { return readOnlyStore{} })
+		require.ErrorIs(t, err, wiringErrors.ErrWrongType)
+
+		require.NoError(t, container.Validate())
+		var repo *repository
+		require.NoError(t, container.Resolve(&repo))
+	})
+	t.Run("should bind token registrations", func(t *testing.T) {
+		container := New()
+		err := container.SingletonToken("store", func() *store {
+			return &store{}
+		}, As(readerType))
+		require.NoError(t, err)
+
+		s, err := GetToken[*store](container, "store")
+		require.NoError(t, err)
+		r, err := Get[reader](container)
+		require.NoError(t, err)
+		require.Same(t, s, r)
+		require.False(t, container.HasType(reflect.TypeFor[*store]()))
+	})
+	t.Run("should reject interfaces not implemented", func(t *testing.T) {
+		container := New()
+		err := container.Singleton(func() store {
+			return store{}
+		}, As(readerType))
+		require.ErrorIs(t, err, wiringErrors.ErrInvalidResolver)
+		require.EqualError(t, err, "type 'pkg.store' does not implement 'pkg.reader'")
+		require.False(t, container.HasType(reflect.TypeFor[store]()))
+
+		err = container.Transient(func() *store {
+			return &store{}
+		}, As(reflect.TypeFor[store]()))
+		require.ErrorIs(t, err, wiringErrors.ErrInvalidResolver)
+		err = container.Transient(func() *store {
+			return &store{}
+		}, As(nil))
+		require.ErrorIs(t, err, wiringErrors.ErrInvalidResolver)
+	})
+	t.Run("should apply the duplicate policy to the interfaces", func(t *testing.T) {
+		container := New(WithDuplicatePolicy(DUPLICATE_ERROR))
+		err := container.Singleton(func() reader {
+			return &store{}
+		})
+		require.NoError(t, err)
+
+		err = container.Singleton(func() *store {
+			return &store{}
+		}, As(readerType))
+		require.ErrorIs(t, err, ErrDuplicateRegistration)
+		require.False(t, container.HasType(reflect.TypeFor[*store]()))
+	})
+}
+
+func TestUnmanaged(t *testing.T) {
+	container := New()
+	owned := &gateway{name: "owned"}
+	require.NoError(t, container.Singleton(func() *gateway { return owned }, Unmanaged()))
+	_, err := Get[*gateway](container)
+	require.NoError(t, err)
+
+	require.NoError(t, container.Invalidate(reflect.TypeFor[*gateway]()))
+	_, err = Get[*gateway](container)
+	require.NoError(t, err)
+	require.NoError(t, container.Close(context.Background()))
+	require.False(t, owned.closed.Load())
+}
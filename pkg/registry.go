@@ -0,0 +1,173 @@
+package pkg
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+
+	"github.com/4strodev/wiring/pkg/errors"
+)
+
+// registry is a snapshot of the dependencies registered on a container. A published registry
+// is never modified, registrations publish a modified copy instead. This way resolutions read
+// the registry without taking any lock and always see a consistent set of dependencies.
+type registry struct {
+	typeMapping  typeMap
+	tokenMapping tokenMap
+	groupMapping groupMap
+	// decorators are the decorators of every type or token key in registration order
+	decorators map[string][]*decorator
+}
+
+func newRegistry() *registry {
+	return &registry{
+		typeMapping:  make(map[reflect.Type]*dependencySpec),
+		tokenMapping: make(map[string]*dependencySpec),
+		groupMapping: make(map[reflect.Type]*dependencyGroup),
+		decorators:   make(map[string][]*decorator),
+	}
+}
+
+// clone returns a copy of the registry that can be modified. Groups and decorator slices
+// are shared with the original registry so they must be cloned before being modified.
+func (r *registry) clone() *registry {
+	return &registry{
+		typeMapping:  maps.Clone(r.typeMapping),
+		tokenMapping: maps.Clone(r.tokenMapping),
+		groupMapping: maps.Clone(r.groupMapping),
+		decorators:   maps.Clone(r.decorators),
+	}
+}
+
+func (r *registry) getSpecForToken(token string) (*dependencySpec, error) {
+	spec, abstractionDefined := r.tokenMapping[token]
+	if !abstractionDefined {
+		message := fmt.Sprintf("resolver for token '%s' not set", token)
+		return &dependencySpec{}, errors.NewError(message).WithKind(errors.ErrNotRegistered).WithKey(tokenKey(token))
+	}
+	return spec, nil
+}
+
+func (r *registry) getSpec(reflectType reflect.Type) (*dependencySpec, error) {
+	spec, abstractionDefined := r.typeMapping[reflectType]
+	if !abstractionDefined {
+		message := fmt.Sprintf("resolver for type '%s' not set", reflectType.String())
+		return &dependencySpec{}, errors.NewError(message).WithKind(errors.ErrNotRegistered).WithKey(typeKey(reflectType))
+	}
+	return spec, nil
+}
+
+// getGroup returns the group of the elements of sliceType. Slices registered
+// with their own resolver are not resolved as groups.
+func (r *registry) getGroup(sliceType reflect.Type) (*dependencyGroup, bool) {
+	if sliceType.Kind() != reflect.Slice {
+		return nil, false
+	}
+	if _, registered := r.typeMapping[sliceType]; registered {
+		return nil, false
+	}
+	group, exists := r.groupMapping[sliceType.Elem()]
+	return group, exists
+}
+
+// resolvedDependency returns how the dependency is resolved. Lazy and provider types requested
+// by type that are registered are resolved with their own resolver instead of being created.
+func (r *registry) resolvedDependency(dep dependency) dependency {
+	if dep.deferredType == nil || dep.token != "" {
+		return dep
+	}
+	if _, registered := r.typeMapping[dep.deferredType]; registered {
+		return dependency{refType: dep.deferredType, optional: dep.optional}
+	}
+	return dep
+}
+
+// checkRebind returns an error if the spec cannot be bound to every type bound to the previous
+// spec, like interfaces of the [As] option that the new resolver does not implement
+func (r *registry) checkRebind(previous *dependencySpec, spec *dependencySpec) error {
+	for _, refType := range r.typeMapping.sortedTypes() {
+		if r.typeMapping[refType] == previous && !spec.Type().AssignableTo(refType) {
+			return errors.Errorf("type '%s' cannot replace the resolver of '%s'", spec.Type(), refType).
+				WithKind(errors.ErrWrongType).WithKey(typeKey(refType))
+		}
+	}
+	return nil
+}
+
+// rebind binds the spec to every type and token bound to the previous spec, like the interfaces
+// of the [As] option, and returns their keys
+func (r *registry) rebind(previous *dependencySpec, spec *dependencySpec) []string {
+	var keys []string
+	for _, refType := range r.typeMapping.sortedTypes() {
+		if r.typeMapping[refType] == previous {
+			r.typeMapping[refType] = spec
+			keys = append(keys, typeKey(refType))
+		}
+	}
+	for _, token := range r.tokenMapping.sortedTokens() {
+		if r.tokenMapping[token] == previous {
+			r.tokenMapping[token] = spec
+			keys = append(keys, tokenKey(token))
+		}
+	}
+	return keys
+}
+
+// boundSpec is a spec bound to the key it is resolved with
+type boundSpec struct {
+	key  string
+	spec *dependencySpec
+}
+
+// boundSpecs returns the specs that resolve the dependency, every member for groups. Missing and
+// deferred dependencies have no specs since they are not resolved as part of the chain.
+func (r *registry) boundSpecs(dep dependency) []boundSpec {
+	dep = r.resolvedDependency(dep)
+	if dep.deferredType != nil {
+		return nil
+	}
+
+	if dep.token != "" {
+		if spec, exists := r.tokenMapping[dep.token]; exists {
+			return []boundSpec{{key: tokenKey(dep.token), spec: spec}}
+		}
+		return nil
+	}
+	if group, isGroup := r.getGroup(dep.refType); isGroup {
+		specs := make([]boundSpec, 0, len(group.members))
+		for _, member := range group.members {
+			specs = append(specs, boundSpec{key: groupKey(dep.refType.Elem(), member.name), spec: member.spec})
+		}
+		return specs
+	}
+	if spec, exists := r.typeMapping[dep.refType]; exists {
+		return []boundSpec{{key: typeKey(dep.refType), spec: spec}}
+	}
+	return nil
+}
+
+// snapshot returns the current registry of the container
+func (w *wireContainer) snapshot() *registry {
+	return w.registry.Load()
+}
+
+// updateRegistry applies the update to a copy of the registry and publishes it. Updates
+// are serialized, if the update returns an error the registry is left untouched.
+func (w *wireContainer) updateRegistry(update func(r *registry) error) error {
+	if w.root != nil {
+		return ErrScopeRegistration
+	}
+
+	w.registryMutex.Lock()
+	defer w.registryMutex.Unlock()
+	if w.frozen.Load() {
+		return ErrFrozen
+	}
+	updated := w.registry.Load().clone()
+	err := update(updated)
+	if err != nil {
+		return err
+	}
+	w.registry.Store(updated)
+	return nil
+}
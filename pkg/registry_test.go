@@ -0,0 +1,64 @@
+package pkg
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/4strodev/wiring/pkg/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+// These tests are meaningful when executed with the race detector: go test -race ./...
+func TestConcurrentRegistration(t *testing.T) {
+	t.Run("should register and resolve concurrently", func(t *testing.T) {
+		container := InitializeContainer(t)
+		scope := container.NewScope()
+		var waitGroup sync.WaitGroup
+
+		for i := 0; i < 20; i++ {
+			waitGroup.Add(3)
+			go func() {
+				defer waitGroup.Done()
+				token := fmt.Sprintf("token-%d", i)
+				require.NoError(t, container.SingletonToken(token, mocks.TokenResolver))
+				require.NoError(t, container.TransientGroup(token, mocks.Resolver))
+				require.True(t, container.HasToken(token))
+			}()
+			go func() {
+				defer waitGroup.Done()
+				var abstraction mocks.Abstraction
+				require.NoError(t, container.Resolve(&abstraction))
+				require.NoError(t, scope.Resolve(&abstraction))
+				require.True(t, container.HasType(reflect.TypeFor[mocks.Abstraction]()))
+			}()
+			go func() {
+				defer waitGroup.Done()
+				var abstractions []mocks.Abstraction
+				_ = container.Resolve(&abstractions)
+				_ = container.Validate()
+				_ = container.Registrations()
+			}()
+		}
+		waitGroup.Wait()
+
+		registrations := container.Registrations()
+		require.Len(t, registrations, 42)
+		var abstractions []mocks.Abstraction
+		require.NoError(t, container.Resolve(&abstractions))
+		require.Len(t, abstractions, 20)
+	})
+	t.Run("should not modify published snapshots", func(t *testing.T) {
+		container := InitializeContainer(t).(*wireContainer)
+		snapshot := container.snapshot()
+		require.NoError(t, container.SingletonGroup("first", mocks.Resolver))
+		require.NoError(t, container.Transient(mocks.ResolverWithMessage("replaced")))
+		groupSnapshot := container.snapshot()
+		require.NoError(t, container.SingletonGroup("second", mocks.Resolver))
+
+		require.Empty(t, snapshot.groupMapping)
+		require.Equal(t, SINGLETON, snapshot.typeMapping[reflect.TypeFor[mocks.Abstraction]()].lifeCycle)
+		require.Len(t, groupSnapshot.groupMapping[reflect.TypeFor[mocks.Abstraction]()].members, 1)
+	})
+}
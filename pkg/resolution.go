@@ -0,0 +1,99 @@
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+
+	"github.com/4strodev/wiring/pkg/errors"
+)
+
+// resolution holds the state of a single resolution chain. Every call to the container
+// starts a new resolution that is passed down to the dependencies being resolved.
+type resolution struct {
+	ctx context.Context
+	// container is the container or scope where the dependencies are resolved
+	container *wireContainer
+	// registry is the snapshot of the registered dependencies used by the whole resolution
+	registry *registry
+	steps    []resolutionStep
+}
+
+// resolutionStep is a dependency that is currently being resolved
+type resolutionStep struct {
+	key  string
+	spec *dependencySpec
+	// created is set when the instance of the step is created instead of taken from a cache
+	created bool
+	// active is set while the step is on the chain once a deferred dependency is requested by it
+	active *atomic.Bool
+}
+
+func newResolution(ctx context.Context, container *wireContainer) *resolution {
+	return &resolution{ctx: ctx, container: container, registry: container.snapshot()}
+}
+
+// enter adds the spec to the active chain. If the spec is already being resolved
+// a circular dependency error is returned containing the whole cycle.
+func (r *resolution) enter(key string, spec *dependencySpec) error {
+	err := r.ctx.Err()
+	if err != nil {
+		return r.fail(errors.ErrCancelled, key, "resolution of %s aborted: %w", key, err)
+	}
+
+	for i, step := range r.steps {
+		if step.spec != spec {
+			continue
+		}
+
+		cycle := make([]string, 0, len(r.steps)-i+1)
+		for _, cycleStep := range r.steps[i:] {
+			cycle = append(cycle, cycleStep.key)
+		}
+		cycle = append(cycle, key)
+		return r.fail(errors.ErrCircularDependency, key, "circular dependency detected: %s", strings.Join(cycle, " -> "))
+	}
+
+	r.steps = append(r.steps, resolutionStep{key: key, spec: spec})
+	return nil
+}
+
+// leave removes the last spec from the active chain
+func (r *resolution) leave() {
+	step := r.steps[len(r.steps)-1]
+	if step.active != nil {
+		step.active.Store(false)
+	}
+	r.steps = r.steps[:len(r.steps)-1]
+}
+
+// current returns the key of the dependency being resolved
+func (r *resolution) current() string {
+	if len(r.steps) == 0 {
+		return ""
+	}
+	return r.steps[len(r.steps)-1].key
+}
+
+// fail creates an error of the provided kind for the key. The path of the error is the
+// active chain ending with the key.
+func (r *resolution) fail(kind errors.Kind, key string, message string, arguments ...any) *errors.WiringError {
+	path := make([]string, 0, len(r.steps)+1)
+	for _, step := range r.steps {
+		path = append(path, step.key)
+	}
+	if r.current() != key {
+		path = append(path, key)
+	}
+	return errors.Errorf(message, arguments...).WithKind(kind).WithKey(key).WithPath(path)
+}
+
+func typeKey(refType reflect.Type) string {
+	return refType.String()
+}
+
+func tokenKey(token string) string {
+	return fmt.Sprintf("'%s'", token)
+}
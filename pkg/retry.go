@@ -0,0 +1,129 @@
+package pkg
+
+import (
+	"context"
+	stderrors "errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/4strodev/wiring/pkg/errors"
+)
+
+// RetryPolicy defines how a failing resolver is retried. Only the errors returned by the resolver
+// are retried, the failures of its dependencies are retried by their own policies.
+type RetryPolicy struct {
+	// Attempts is the number of times the resolver is called, including the first one
+	Attempts int
+	// Backoff is the delay before the first retry, it is doubled on every retry
+	Backoff time.Duration
+	// MaxBackoff limits the delay between retries, there is no limit when it is zero
+	MaxBackoff time.Duration
+	// Jitter is the fraction of every delay, from 0 to 1, that is randomly subtracted so
+	// resolutions failing at the same time do not retry at the same time
+	Jitter float64
+	// Timeout limits every attempt, the context received by the resolver is cancelled once it expires
+	Timeout time.Duration
+	// FailureTTL memoizes the error once every attempt fails. Until it expires resolutions
+	// fail with the same error without calling the resolver.
+	FailureTTL time.Duration
+}
+
+// Retry sets the retry policy of the dependency. Delays are measured by the clock of the container,
+// see [WithClock].
+//
+//	container.Singleton(NewDatabase, wiring.Retry(wiring.RetryPolicy{
+//		Attempts: 5,
+//		Backoff:  100 * time.Millisecond,
+//		Jitter:   0.5,
+//	}))
+func Retry(policy RetryPolicy) RegistrationOption {
+	return func(registration *registrationOptions) {
+		registration.retry = &policy
+	}
+}
+
+// failure is the error of a resolver memoized until it expires
+type failure struct {
+	err     error
+	expires time.Time
+}
+
+// delay returns the time to wait before the retry, the first retry is 1
+func (policy *RetryPolicy) delay(retry int) time.Duration {
+	delay := policy.Backoff << (retry - 1)
+	if delay < 0 || (policy.MaxBackoff > 0 && delay > policy.MaxBackoff) {
+		delay = policy.MaxBackoff
+	}
+	if policy.Jitter > 0 {
+		delay -= time.Duration(rand.Float64() * min(policy.Jitter, 1) * float64(delay))
+	}
+	return delay
+}
+
+// isResolverFailure reports if the error was returned by the resolver of the key
+func isResolverFailure(err error, key string) bool {
+	var wiringErr *errors.WiringError
+	return stderrors.As(err, &wiringErr) && wiringErr.Kind == errors.ErrResolverFailed && wiringErr.Key == key
+}
+
+// executeWithRetry executes the resolver following the retry policy of the spec
+func (spec *dependencySpec) executeWithRetry(res *resolution, key string) (any, error) {
+	policy := spec.retry
+	if policy == nil {
+		return spec.executeResolver(res)
+	}
+
+	clock := spec.container.clock
+	memoized := spec.failure.Load()
+	if memoized != nil && clock.Now().Before(memoized.expires) {
+		return nil, memoized.err
+	}
+
+	var err error
+	attempts := 0
+	for {
+		var instance any
+		instance, err = spec.attempt(res, policy.Timeout)
+		attempts++
+		if err == nil {
+			spec.failure.Store(nil)
+			return instance, nil
+		}
+		if attempts >= policy.Attempts || !isResolverFailure(err, key) {
+			break
+		}
+
+		select {
+		case <-clock.After(policy.delay(attempts)):
+		case <-res.ctx.Done():
+			return nil, res.fail(errors.ErrCancelled, key, "resolution of %s aborted while retrying: %w", key, res.ctx.Err())
+		}
+	}
+
+	if !isResolverFailure(err, key) {
+		return nil, err
+	}
+	if attempts > 1 {
+		err = errors.Errorf("%s failed after %d attempts: %w", key, attempts, err)
+	}
+	if policy.FailureTTL > 0 {
+		spec.failure.Store(&failure{err: err, expires: clock.Now().Add(policy.FailureTTL)})
+	}
+	return nil, err
+}
+
+// attempt executes the resolver once, the context of the resolution is limited by the timeout
+func (spec *dependencySpec) attempt(res *resolution, timeout time.Duration) (any, error) {
+	if timeout <= 0 {
+		return spec.executeResolver(res)
+	}
+
+	ctx := res.ctx
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	res.ctx = attemptCtx
+	defer func() {
+		res.ctx = ctx
+	}()
+	return spec.executeResolver(res)
+}
@@ -0,0 +1,166 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	wiringErrors "github.com/4strodev/wiring/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock advances its time when it is waited instead of sleeping
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+	waits []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.waits = append(c.waits, d)
+	c.now = c.now.Add(d)
+	ticks := make(chan time.Time, 1)
+	ticks <- c.now
+	return ticks
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// flakyResolver fails until it has been called the provided times
+func flakyResolver(failures int, calls *int) func() (*counter, error) {
+	return func() (*counter, error) {
+		*calls++
+		if *calls <= failures {
+			return nil, errors.New("connection refused")
+		}
+		return &counter{value: *calls}, nil
+	}
+}
+
+func TestRetry(t *testing.T) {
+	t.Run("should retry with exponential backoff", func(t *testing.T) {
+		clock := newFakeClock()
+		container := New(WithClock(clock))
+		calls := 0
+		require.NoError(t, container.Singleton(flakyResolver(3, &calls), Retry(RetryPolicy{
+			Attempts:   5,
+			Backoff:    100 * time.Millisecond,
+			MaxBackoff: 300 * time.Millisecond,
+		})))
+
+		instance, err := Get[*counter](container)
+		require.NoError(t, err)
+		require.Equal(t, 4, instance.value)
+		require.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond}, clock.waits)
+	})
+
+	t.Run("should fail once every attempt fails", func(t *testing.T) {
+		clock := newFakeClock()
+		container := New(WithClock(clock))
+		calls := 0
+		require.NoError(t, container.Transient(flakyResolver(10, &calls), Retry(RetryPolicy{Attempts: 3})))
+
+		_, err := Get[*counter](container)
+		require.ErrorIs(t, err, wiringErrors.ErrResolverFailed)
+		require.ErrorContains(t, err, "*pkg.counter failed after 3 attempts: resolver of *pkg.counter failed: connection refused")
+		require.Equal(t, 3, calls)
+	})
+
+	t.Run("should randomize the delays with the jitter", func(t *testing.T) {
+		clock := newFakeClock()
+		container := New(WithClock(clock))
+		calls := 0
+		require.NoError(t, container.Singleton(flakyResolver(5, &calls), Retry(RetryPolicy{
+			Attempts: 6,
+			Backoff:  time.Second,
+			Jitter:   0.5,
+		})))
+
+		_, err := Get[*counter](container)
+		require.NoError(t, err)
+		require.Len(t, clock.waits, 5)
+		for i, wait := range clock.waits {
+			backoff := time.Second << i
+			require.LessOrEqual(t, wait, backoff)
+			require.GreaterOrEqual(t, wait, backoff/2)
+		}
+	})
+
+	t.Run("should not retry the failures of the dependencies", func(t *testing.T) {
+		container := New(WithClock(newFakeClock()))
+		calls := 0
+		require.NoError(t, container.Singleton(flakyResolver(10, &calls)))
+		require.NoError(t, container.Singleton(func(c *counter) *lazyB { return &lazyB{} }, Retry(RetryPolicy{Attempts: 3})))
+
+		_, err := Get[*lazyB](container)
+		require.ErrorContains(t, err, "resolver of *pkg.counter failed: connection refused")
+		require.NotContains(t, err.Error(), "attempts")
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("should limit every attempt with the timeout", func(t *testing.T) {
+		container := New()
+		attempts := 0
+		require.NoError(t, container.Singleton(func(ctx context.Context) (*counter, error) {
+			attempts++
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}, Retry(RetryPolicy{Attempts: 2, Timeout: 5 * time.Millisecond})))
+
+		_, err := Get[*counter](container)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Equal(t, 2, attempts)
+	})
+
+	t.Run("should stop retrying when the context is cancelled", func(t *testing.T) {
+		container := New()
+		ctx, cancel := context.WithCancel(context.Background())
+		require.NoError(t, container.Singleton(func() (*counter, error) {
+			cancel()
+			return nil, errors.New("connection refused")
+		}, Retry(RetryPolicy{Attempts: 3, Backoff: time.Hour})))
+
+		var instance *counter
+		err := container.ResolveContext(ctx, &instance)
+		require.ErrorIs(t, err, wiringErrors.ErrCancelled)
+	})
+
+	t.Run("should memoize the failure", func(t *testing.T) {
+		clock := newFakeClock()
+		container := New(WithClock(clock))
+		calls := 0
+		require.NoError(t, container.Singleton(flakyResolver(2, &calls), Retry(RetryPolicy{
+			Attempts:   2,
+			FailureTTL: time.Minute,
+		})))
+
+		_, err := Get[*counter](container)
+		require.Error(t, err)
+		_, memoized := Get[*counter](container)
+		require.Equal(t, err, memoized)
+		require.Equal(t, 2, calls)
+
+		clock.Advance(time.Minute)
+		instance, err := Get[*counter](container)
+		require.NoError(t, err)
+		require.Equal(t, 3, instance.value)
+	})
+}
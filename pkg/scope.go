@@ -0,0 +1,80 @@
+package pkg
+
+import (
+	"sync"
+
+	"github.com/4strodev/wiring/pkg/errors"
+)
+
+// ErrScopeRegistration is returned when registering dependencies on a scope
+var ErrScopeRegistration = errors.NewError("dependencies cannot be registered on a scope")
+
+// scopedInstance holds the instance of a scoped dependency inside a scope
+type scopedInstance struct {
+	instance    any
+	decorations map[string]decoration
+	mutex       sync.Mutex
+}
+
+// NewScope implements pkg.Container.
+func (w *wireContainer) NewScope() Container {
+	root := w
+	if w.root != nil {
+		root = w.root
+	}
+
+	return &wireContainer{
+		registry:        root.registry,
+		root:            root,
+		scopedInstances: make(map[*dependencySpec]*scopedInstance),
+	}
+}
+
+// Scoped implements pkg.Container.
+func (w *wireContainer) Scoped(resolver any, options ...RegistrationOption) error {
+	return w.registerType(resolver, SCOPED, options)
+}
+
+// ScopedToken implements pkg.Container.
+func (w *wireContainer) ScopedToken(token string, resolver any, options ...RegistrationOption) error {
+	return w.registerToken(token, resolver, SCOPED, options)
+}
+
+// resolveScoped returns the instance of the spec cached by the scope, creating it if needed
+func (w *wireContainer) resolveScoped(res *resolution, key string, spec *dependencySpec) (any, error) {
+	if w.root == nil {
+		return nil, res.fail(errors.ErrOutOfScope, key, "scoped dependency %s cannot be resolved outside a scope", key)
+	}
+
+	w.scopedMutex.Lock()
+	scoped, exists := w.scopedInstances[spec]
+	if !exists {
+		scoped = new(scopedInstance)
+		w.scopedInstances[spec] = scoped
+	}
+	w.scopedMutex.Unlock()
+
+	scoped.mutex.Lock()
+	defer scoped.mutex.Unlock()
+	if scoped.instance == nil {
+		instance, err := spec.create(res, key)
+		if err != nil {
+			return nil, err
+		}
+		if instance == nil {
+			return nil, res.fail(errors.ErrNilInstance, key, "Resolver returned a nil instance")
+		}
+		if !w.instantiated(spec, key, instance) {
+			w.closeDiscarded(spec, key, instance)
+			return nil, ErrClosed
+		}
+		scoped.instance = instance
+	}
+
+	return decorate(res, key, scoped.instance, &scoped.decorations)
+}
+
+// isClosed reports if the container or the root of the scope has been closed
+func (w *wireContainer) isClosed() bool {
+	return w.closed.Load() || (w.root != nil && w.root.closed.Load())
+}
@@ -0,0 +1,140 @@
+package pkg
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	wiringErrors "github.com/4strodev/wiring/pkg/errors"
+	"github.com/4strodev/wiring/pkg/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScoped(t *testing.T) {
+	newScopedContainer := func(t *testing.T, counter *int) Container {
+		container := InitializeContainer(t)
+		err := container.Scoped(func(abstraction mocks.Abstraction) ComplexAbstraction {
+			*counter++
+			return &ComplexImplementation{Abstraction: abstraction}
+		})
+		require.NoError(t, err)
+		return container
+	}
+
+	t.Run("should cache one instance per scope", func(t *testing.T) {
+		var counter int
+		container := newScopedContainer(t, &counter)
+		scope := container.NewScope()
+		otherScope := container.NewScope()
+
+		var first, second, other ComplexAbstraction
+		require.NoError(t, scope.Resolve(&first))
+		require.NoError(t, scope.Resolve(&second))
+		require.NoError(t, otherScope.Resolve(&other))
+		require.Same(t, first, second)
+		require.NotSame(t, first, other)
+		require.Equal(t, 2, counter)
+
+		// Singletons are shared between scopes
+		require.Same(t, first.(*ComplexImplementation).Abstraction, other.(*ComplexImplementation).Abstraction)
+	})
+	t.Run("should resolve scoped tokens", func(t *testing.T) {
+		container := New()
+		err := container.ScopedToken("scoped", func() *mocks.Implementation {
+			return &mocks.Implementation{}
+		})
+		require.NoError(t, err)
+		scope := container.NewScope()
+
+		var first, second *mocks.Implementation
+		require.NoError(t, scope.ResolveToken("scoped", &first))
+		require.NoError(t, scope.ResolveToken("scoped", &second))
+		require.Same(t, first, second)
+	})
+	t.Run("should resolve dependencies of scoped dependencies inside the scope", func(t *testing.T) {
+		container := New()
+		err := container.Scoped(mocks.Resolver)
+		require.NoError(t, err)
+		err = container.Transient(func(abstraction mocks.Abstraction) ComplexAbstraction {
+			return ComplexImplementation{Abstraction: abstraction}
+		})
+		require.NoError(t, err)
+		scope := container.NewScope()
+
+		var abstraction mocks.Abstraction
+		var complexAbstraction ComplexAbstraction
+		require.NoError(t, scope.Resolve(&abstraction))
+		require.NoError(t, scope.Resolve(&complexAbstraction))
+		require.Same(t, abstraction, complexAbstraction.(ComplexImplementation).Abstraction)
+	})
+	t.Run("should not resolve scoped dependencies outside a scope", func(t *testing.T) {
+		var counter int
+		container := newScopedContainer(t, &counter)
+		var complexAbstraction ComplexAbstraction
+		err := container.Resolve(&complexAbstraction)
+		require.EqualError(t, err, "scoped dependency pkg.ComplexAbstraction cannot be resolved outside a scope")
+	})
+	t.Run("should not let singletons capture scoped dependencies", func(t *testing.T) {
+		container := New()
+		err := container.Scoped(mocks.Resolver)
+		require.NoError(t, err)
+		err = container.Singleton(func(abstraction mocks.Abstraction) ComplexAbstraction {
+			return ComplexImplementation{Abstraction: abstraction}
+		})
+		require.NoError(t, err)
+
+		var complexAbstraction ComplexAbstraction
+		err = container.NewScope().Resolve(&complexAbstraction)
+		require.Error(t, err)
+		require.EqualError(t, container.Validate(), "singleton pkg.ComplexAbstraction depends on scoped type 'mocks.Abstraction'")
+	})
+	t.Run("should not let singletons capture scoped dependencies through transients", func(t *testing.T) {
+		container := New()
+		require.NoError(t, container.Scoped(mocks.Resolver))
+		require.NoError(t, container.Transient(func(abstraction mocks.Abstraction) *counter {
+			return &counter{}
+		}))
+		require.NoError(t, container.Singleton(func(*counter) ComplexAbstraction {
+			return ComplexImplementation{}
+		}))
+
+		_, err := Get[*counter](container.NewScope())
+		require.NoError(t, err)
+		err = container.Validate()
+		require.ErrorIs(t, err, wiringErrors.ErrOutOfScope)
+		require.EqualError(t, err, "singleton pkg.ComplexAbstraction depends on scoped type 'mocks.Abstraction'")
+		require.ErrorIs(t, container.Build(), wiringErrors.ErrOutOfScope)
+	})
+	t.Run("should not register dependencies on a scope", func(t *testing.T) {
+		scope := New().NewScope()
+		require.ErrorIs(t, scope.Singleton(mocks.Resolver), ErrScopeRegistration)
+		require.ErrorIs(t, scope.Transient(mocks.Resolver), ErrScopeRegistration)
+		require.ErrorIs(t, scope.Scoped(mocks.Resolver), ErrScopeRegistration)
+		require.ErrorIs(t, scope.SingletonToken("token", mocks.Resolver), ErrScopeRegistration)
+		require.ErrorIs(t, scope.TransientToken("token", mocks.Resolver), ErrScopeRegistration)
+		require.ErrorIs(t, scope.ScopedToken("token", mocks.Resolver), ErrScopeRegistration)
+	})
+	t.Run("should close only the scoped instances", func(t *testing.T) {
+		var closed []string
+		container := New()
+		err := container.Singleton(func() *closableResource {
+			return &closableResource{name: "singleton", closed: &closed}
+		})
+		require.NoError(t, err)
+		err = container.Scoped(func(*closableResource) io.Closer {
+			return &closableResource{name: "scoped", closed: &closed}
+		})
+		require.NoError(t, err)
+
+		scope := container.NewScope()
+		var closer io.Closer
+		require.NoError(t, scope.Resolve(&closer))
+		require.NoError(t, scope.Close(context.Background()))
+		require.Equal(t, []string{"scoped"}, closed)
+		require.ErrorIs(t, scope.Resolve(&closer), ErrClosed)
+
+		require.NoError(t, container.Close(context.Background()))
+		require.Equal(t, []string{"scoped", "singleton"}, closed)
+		require.ErrorIs(t, container.NewScope().Resolve(&closer), ErrClosed)
+	})
+}
@@ -0,0 +1,200 @@
+package pkg
+
+import (
+	stderrors "errors"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/4strodev/wiring/pkg/errors"
+)
+
+// In marks a struct as a parameter object. When a resolver expects a struct embedding In
+// the container creates the struct and fills its fields following the same rules as Fill.
+// This allows resolvers to request dependencies by token.
+//
+//	type RepositoryParams struct {
+//		wiring.In
+//		Primary *sql.DB `wire:"primaryDB"`
+//		Replica *sql.DB `wire:"replicaDB"`
+//	}
+//
+//	container.Singleton(func(params RepositoryParams) *Repository { ... })
+type In struct{}
+
+var inType = reflect.TypeFor[In]()
+
+// wiredField is a struct field that has to be resolved by the container
+type wiredField struct {
+	index int
+	name  string
+	dependency
+	// fill is set for structs and struct pointers whose fields are wired recursively
+	fill bool
+	// embedded structs are resolved by type when they are registered, otherwise they are filled
+	embedded bool
+}
+
+// isParameterObject reports if the type is a struct embedding [In]
+func isParameterObject(refType reflect.Type) bool {
+	if refType.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < refType.NumField(); i++ {
+		field := refType.Field(i)
+		if field.Anonymous && field.Type == inType {
+			return true
+		}
+	}
+	return false
+}
+
+// isFillable reports if the type is a struct or a pointer to a struct
+func isFillable(refType reflect.Type) bool {
+	if refType.Kind() == reflect.Pointer {
+		refType = refType.Elem()
+	}
+	return refType.Kind() == reflect.Struct
+}
+
+// wiredFields returns the fields of the struct the container has to resolve. Unexported fields,
+// fields tagged with wire:",ignore" and the [In] marker are skipped. Fields tagged with wire:",fill"
+// are filled recursively, like embedded structs whose type is not registered.
+func wiredFields(structType reflect.Type) []wiredField {
+	fields := make([]wiredField, 0, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		fieldType := structType.Field(i)
+		if fieldType.Anonymous && fieldType.Type == inType {
+			continue
+		}
+		// Promoted fields of unexported embedded structs can still be set, unless the
+		// struct is embedded by pointer because it cannot be allocated.
+		embedded := fieldType.Anonymous && isFillable(fieldType.Type)
+		if !fieldType.IsExported() && !(embedded && fieldType.Type.Kind() == reflect.Struct) {
+			continue
+		}
+
+		tagParams := strings.Split(fieldType.Tag.Get(WIRE_TAG), ",")
+		options := tagParams[1:]
+		if slices.Contains(options, "ignore") {
+			continue
+		}
+
+		field := wiredField{index: i, name: fieldType.Name}
+		switch {
+		case tagParams[0] != "":
+			field.dependency = newDependency(fieldType.Type, tagParams[0])
+		case slices.Contains(options, "fill") || (embedded && !fieldType.IsExported()):
+			field.refType = fieldType.Type
+			field.fill = true
+		default:
+			field.dependency = newDependency(fieldType.Type, "")
+			field.embedded = embedded && field.deferredType == nil
+		}
+		field.optional = slices.Contains(options, "optional")
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// filled reports if the field is filled recursively with the registry instead of being resolved
+func (field wiredField) filled(r *registry) bool {
+	if field.embedded {
+		_, registered := r.typeMapping[field.refType]
+		return !registered
+	}
+	return field.fill
+}
+
+// fieldDependencies returns the dependencies needed to fill the struct with the registry,
+// including the ones of the structs filled recursively
+func fieldDependencies(r *registry, structType reflect.Type, parents []reflect.Type) []dependency {
+	if slices.Contains(parents, structType) {
+		return nil
+	}
+	parents = append(parents, structType)
+
+	var dependencies []dependency
+	for _, field := range wiredFields(structType) {
+		switch {
+		case field.filled(r):
+			nestedType := field.refType
+			if nestedType.Kind() == reflect.Pointer {
+				nestedType = nestedType.Elem()
+			}
+			if nestedType.Kind() == reflect.Struct {
+				dependencies = append(dependencies, fieldDependencies(r, nestedType, parents)...)
+			}
+		case field.refType != contextType:
+			dependencies = append(dependencies, field.dependency)
+		}
+	}
+	return dependencies
+}
+
+// isNotRegistered reports if the error was caused because the key is not registered
+func isNotRegistered(err error, key string) bool {
+	var wiringErr *errors.WiringError
+	return stderrors.As(err, &wiringErr) && wiringErr.Kind == errors.ErrNotRegistered && wiringErr.Key == key
+}
+
+// fillStruct resolves the wired fields of the struct as part of the resolution
+func (w *wireContainer) fillStruct(structValue reflect.Value, res *resolution) error {
+	return w.fillFields(structValue, res, nil)
+}
+
+// fillFields resolves the wired fields of the struct. Parents are the structs being filled
+// recursively, they are used to reject structs that contain themselves.
+func (w *wireContainer) fillFields(structValue reflect.Value, res *resolution, parents []reflect.Type) error {
+	structType := structValue.Type()
+	if slices.Contains(parents, structType) {
+		return res.fail(errors.ErrCircularDependency, typeKey(structType), "struct %s cannot be filled recursively, it contains itself", structType)
+	}
+	parents = append(parents, structType)
+
+	for _, field := range wiredFields(structType) {
+		fieldValue := structValue.Field(field.index)
+		if field.filled(res.registry) {
+			err := w.fillNested(fieldValue, field.name, res, parents)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		instance, err := w.resolveDependency(field.dependency, res)
+		if err != nil {
+			if field.optional && isNotRegistered(err, field.key()) {
+				continue
+			}
+			return errors.Errorf("error resolving field '%s': %w", field.name, err)
+		}
+
+		if !reflect.TypeOf(instance).AssignableTo(fieldValue.Type()) {
+			return errors.Errorf("error resolving field '%s': wrong resolver for type %v", field.name, fieldValue.Type()).WithKind(errors.ErrWrongType).WithKey(field.key())
+		}
+		fieldValue.Set(reflect.ValueOf(instance))
+	}
+	return nil
+}
+
+// fillNested fills a field tagged with wire:",fill" or an embedded struct that is not registered. Nil struct pointers
+// are allocated before being filled.
+func (w *wireContainer) fillNested(fieldValue reflect.Value, name string, res *resolution, parents []reflect.Type) error {
+	if !isFillable(fieldValue.Type()) {
+		return errors.Errorf("error filling field '%s': %v is not a struct or a struct pointer", name, fieldValue.Type()).WithKind(errors.ErrWrongType)
+	}
+
+	if fieldValue.Kind() == reflect.Pointer {
+		if fieldValue.IsNil() {
+			fieldValue.Set(reflect.New(fieldValue.Type().Elem()))
+		}
+		fieldValue = fieldValue.Elem()
+	}
+
+	err := w.fillFields(fieldValue, res, parents)
+	if err != nil {
+		return errors.Errorf("error filling field '%s': %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package pkg
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	wiringErrors "github.com/4strodev/wiring/pkg/errors"
+	"github.com/4strodev/wiring/pkg/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+type database struct {
+	name string
+}
+
+type repositoryParams struct {
+	In
+	Ctx         context.Context
+	Primary     *database `wire:"primaryDB"`
+	Replica     *database `wire:"replicaDB"`
+	Abstraction mocks.Abstraction
+	Ignored     string `wire:",ignore"`
+	ignored     string
+}
+
+type repository struct {
+	primary *database
+	replica *database
+}
+
+func newDatabasesContainer(t *testing.T) Container {
+	container := InitializeContainer(t)
+	err := container.SingletonToken("primaryDB", func() *database {
+		return &database{name: "primary"}
+	})
+	require.NoError(t, err)
+	err = container.SingletonToken("replicaDB", func() *database {
+		return &database{name: "replica"}
+	})
+	require.NoError(t, err)
+	return container
+}
+
+func TestParameterObjects(t *testing.T) {
+	t.Run("should resolve parameter object fields by token and type", func(t *testing.T) {
+		container := newDatabasesContainer(t)
+		err := container.Singleton(func(ctx context.Context, params repositoryParams) *repository {
+			require.NotNil(t, params.Abstraction)
+			require.NotNil(t, params.Ctx)
+			require.Empty(t, params.Ignored)
+			require.Empty(t, params.ignored)
+			return &repository{primary: params.Primary, replica: params.Replica}
+		})
+		require.NoError(t, err)
+
+		var repo *repository
+		require.NoError(t, container.Resolve(&repo))
+		require.Equal(t, "primary", repo.primary.name)
+		require.Equal(t, "replica", repo.replica.name)
+		require.NoError(t, container.Validate())
+	})
+	t.Run("should report missing tokens", func(t *testing.T) {
+		container := InitializeContainer(t)
+		err := container.Transient(func(params repositoryParams) *repository {
+			return &repository{}
+		})
+		require.NoError(t, err)
+
+		var repo *repository
+		err = container.Resolve(&repo)
+		require.EqualError(t, err, "error resolving field 'Primary': resolver for token 'primaryDB' not set")
+		require.EqualError(t, container.Validate(), "resolver for *pkg.repository requires token 'primaryDB' which is not set\n"+
+			"resolver for *pkg.repository requires token 'replicaDB' which is not set")
+	})
+	t.Run("should describe token dependencies", func(t *testing.T) {
+		container := newDatabasesContainer(t)
+		err := container.Singleton(func(params repositoryParams) *repository {
+			return &repository{}
+		})
+		require.NoError(t, err)
+
+		registrations := container.Registrations()
+		require.Equal(t, "*pkg.repository", registrations[0].Key)
+		require.Equal(t, []string{"primaryDB", "replicaDB"}, registrations[0].Tokens)
+	})
+}
+
+type greeters struct {
+	Greeter mocks.Abstraction
+	Message string `wire:"token"`
+}
+
+type OptionalReader struct {
+	Reader io.Reader `wire:",optional"`
+}
+
+type nestedStruct struct {
+	greeters
+	*OptionalReader
+	Pointer  *greeters `wire:",fill"`
+	Value    greeters  `wire:",fill"`
+	Optional *database `wire:"primaryDB,optional"`
+}
+
+type recursiveStruct struct {
+	Next *recursiveStruct `wire:",fill"`
+}
+
+type optionalParams struct {
+	In
+	Primary  *database `wire:"primaryDB,optional"`
+	Replica  *database `wire:"replicaDB,optional"`
+	Greeters *greeters `wire:",fill"`
+}
+
+func TestFillOptions(t *testing.T) {
+	t.Run("should fill nested and embedded structs", func(t *testing.T) {
+		container := InitializeContainer(t)
+
+		var nested nestedStruct
+		require.NoError(t, container.Fill(&nested))
+		require.NotNil(t, nested.greeters.Greeter)
+		require.Equal(t, mocks.DEFAULT_MESSAGE, nested.greeters.Message)
+		require.NotNil(t, nested.OptionalReader)
+		require.Nil(t, nested.Reader)
+		require.NotNil(t, nested.Pointer)
+		require.Equal(t, mocks.DEFAULT_MESSAGE, nested.Pointer.Message)
+		require.Equal(t, mocks.DEFAULT_MESSAGE, nested.Value.Message)
+		require.Nil(t, nested.Optional)
+	})
+	t.Run("should resolve registered embedded structs by type", func(t *testing.T) {
+		type Embedded struct{ Port int }
+		container := New()
+		registered := &Embedded{Port: 8080}
+		require.NoError(t, container.Singleton(func() *Embedded { return registered }))
+
+		var embedding struct{ *Embedded }
+		require.NoError(t, container.Fill(&embedding))
+		require.Same(t, registered, embedding.Embedded)
+
+		type server struct{ port int }
+		type serverParams struct {
+			In
+			*Embedded
+		}
+		require.NoError(t, container.Singleton(func(params serverParams) *server {
+			return &server{port: params.Port}
+		}))
+		require.NoError(t, container.Build())
+		instance, err := Get[*server](container)
+		require.NoError(t, err)
+		require.Equal(t, 8080, instance.port)
+	})
+	t.Run("should keep allocated struct pointers", func(t *testing.T) {
+		container := InitializeContainer(t)
+
+		pointer := &greeters{}
+		nested := nestedStruct{Pointer: pointer}
+		require.NoError(t, container.Fill(&nested))
+		require.Same(t, pointer, nested.Pointer)
+		require.NotNil(t, pointer.Greeter)
+	})
+	t.Run("should resolve optional fields when they are registered", func(t *testing.T) {
+		container := newDatabasesContainer(t)
+
+		var nested nestedStruct
+		require.NoError(t, container.Fill(&nested))
+		require.Equal(t, "primary", nested.Optional.name)
+	})
+	t.Run("should fail when a dependency of an optional field is not registered", func(t *testing.T) {
+		container := InitializeContainer(t)
+		err := container.SingletonToken("primaryDB", func(io.Reader) *database {
+			return &database{}
+		})
+		require.NoError(t, err)
+
+		var nested nestedStruct
+		err = container.Fill(&nested)
+		require.ErrorIs(t, err, wiringErrors.ErrNotRegistered)
+		require.EqualError(t, err, "error resolving field 'Optional': resolver for type 'io.Reader' not set")
+	})
+	t.Run("should return the error of nested fields", func(t *testing.T) {
+		container := New()
+
+		var nested nestedStruct
+		err := container.Fill(&nested)
+		require.ErrorIs(t, err, wiringErrors.ErrNotRegistered)
+		require.EqualError(t, err, "error filling field 'greeters': error resolving field 'Greeter': resolver for type 'mocks.Abstraction' not set")
+	})
+	t.Run("should reject structs that contain themselves", func(t *testing.T) {
+		container := New()
+
+		var recursive recursiveStruct
+		err := container.Fill(&recursive)
+		require.ErrorIs(t, err, wiringErrors.ErrCircularDependency)
+	})
+	t.Run("should reject fill fields that are not structs", func(t *testing.T) {
+		container := New()
+
+		var invalid struct {
+			Value int `wire:",fill"`
+		}
+		err := container.Fill(&invalid)
+		require.ErrorIs(t, err, wiringErrors.ErrWrongType)
+	})
+	t.Run("should apply the options to parameter objects", func(t *testing.T) {
+		for _, build := range []bool{false, true} {
+			container := InitializeContainer(t)
+			err := container.SingletonToken("primaryDB", func() *database {
+				return &database{name: "primary"}
+			})
+			require.NoError(t, err)
+			err = container.Singleton(func(params optionalParams) *repository {
+				require.NotNil(t, params.Greeters.Greeter)
+				return &repository{primary: params.Primary, replica: params.Replica}
+			})
+			require.NoError(t, err)
+			require.NoError(t, container.Validate())
+			if build {
+				require.NoError(t, container.Build())
+			}
+
+			var repo *repository
+			require.NoError(t, container.Resolve(&repo))
+			require.Equal(t, "primary", repo.primary.name)
+			require.Nil(t, repo.replica)
+		}
+	})
+}
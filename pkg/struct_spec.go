@@ -0,0 +1,90 @@
+package pkg
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/4strodev/wiring/pkg/errors"
+)
+
+// Initializer is implemented by structs registered with SingletonStruct or TransientStruct
+// that need to be initialized once their fields are filled
+type Initializer interface {
+	Init() error
+}
+
+// PostConstructor same as [Initializer] for structs that prefer the PostConstruct name.
+// When a struct implements both only Init is called.
+type PostConstructor interface {
+	PostConstruct() error
+}
+
+// newStructSpec creates a spec that builds the struct, or the struct pointer, filling its
+// fields instead of calling a resolver
+func newStructSpec(refType reflect.Type, lifeCycle abstractionLifeCycle, container *wireContainer) (*dependencySpec, error) {
+	if refType == nil || !isFillable(refType) {
+		return nil, errors.Errorf("type '%v' should be a struct or a struct pointer", refType).WithKind(errors.ErrInvalidResolver)
+	}
+
+	spec := new(dependencySpec)
+	spec.lifeCycle = lifeCycle
+	spec.container = container
+	spec.source = registrationSource()
+	spec.returnType = refType
+	spec.structType = refType
+	if refType.Kind() == reflect.Pointer {
+		spec.structType = refType.Elem()
+	}
+	return spec, nil
+}
+
+// construct allocates the struct of the spec, fills it and calls its initialization hook
+func (spec *dependencySpec) construct(res *resolution) (any, error) {
+	value := reflect.New(spec.structType)
+	err := res.container.fillStruct(value.Elem(), res)
+	if err != nil {
+		return nil, err
+	}
+
+	switch hook := value.Interface().(type) {
+	case Initializer:
+		err = hook.Init()
+	case PostConstructor:
+		err = hook.PostConstruct()
+	}
+	if err != nil {
+		key := res.current()
+		return nil, res.fail(errors.ErrResolverFailed, key, "initialization of %s failed: %w", key, err)
+	}
+
+	if spec.returnType.Kind() == reflect.Pointer {
+		return value.Interface(), nil
+	}
+	return value.Elem().Interface(), nil
+}
+
+// signature describes how the spec is resolved
+func (spec *dependencySpec) signature() string {
+	if spec.structType != nil {
+		return fmt.Sprintf("struct %s", spec.returnType)
+	}
+	return reflect.TypeOf(spec.resolver).String()
+}
+
+// SingletonStruct implements pkg.Container.
+func (w *wireContainer) SingletonStruct(refType reflect.Type, options ...RegistrationOption) error {
+	spec, err := newStructSpec(refType, SINGLETON, w)
+	if err != nil {
+		return err
+	}
+	return w.registerSpec(spec, newRegistrationOptions(options))
+}
+
+// TransientStruct implements pkg.Container.
+func (w *wireContainer) TransientStruct(refType reflect.Type, options ...RegistrationOption) error {
+	spec, err := newStructSpec(refType, TRANSIENT, w)
+	if err != nil {
+		return err
+	}
+	return w.registerSpec(spec, newRegistrationOptions(options))
+}
@@ -0,0 +1,127 @@
+package pkg
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	wiringErrors "github.com/4strodev/wiring/pkg/errors"
+	"github.com/4strodev/wiring/pkg/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+type greeter interface {
+	Greet()
+}
+
+type greeterService struct {
+	Greeter mocks.Abstraction
+	Message string `wire:"token"`
+	inits   int
+}
+
+func (s *greeterService) Init() error {
+	s.inits++
+	return nil
+}
+
+func (s *greeterService) Greet() {
+	s.Greeter.Greet()
+}
+
+var errInitFailed = errors.New("init failed")
+
+type failingService struct {
+	Greeter mocks.Abstraction
+}
+
+func (s *failingService) Init() error {
+	return errInitFailed
+}
+
+type postConstructed struct {
+	Message     string `wire:"token"`
+	constructed bool
+}
+
+func (p *postConstructed) PostConstruct() error {
+	p.constructed = true
+	return nil
+}
+
+func TestStructRegistration(t *testing.T) {
+	t.Run("should build and cache singleton structs", func(t *testing.T) {
+		container := InitializeContainer(t)
+		require.NoError(t, SingletonStruct[*greeterService](container))
+
+		first, err := Get[*greeterService](container)
+		require.NoError(t, err)
+		second, err := Get[*greeterService](container)
+		require.NoError(t, err)
+		require.Same(t, first, second)
+		require.NotNil(t, first.Greeter)
+		require.Equal(t, mocks.DEFAULT_MESSAGE, first.Message)
+		require.Equal(t, 1, first.inits)
+	})
+	t.Run("should build a new struct every time for transients", func(t *testing.T) {
+		container := InitializeContainer(t)
+		require.NoError(t, TransientStruct[postConstructed](container))
+
+		first, err := Get[postConstructed](container)
+		require.NoError(t, err)
+		require.True(t, first.constructed)
+		require.Equal(t, mocks.DEFAULT_MESSAGE, first.Message)
+
+		second, err := Get[postConstructed](container)
+		require.NoError(t, err)
+		require.True(t, second.constructed)
+	})
+	t.Run("should bind the struct to the interfaces provided", func(t *testing.T) {
+		container := InitializeContainer(t)
+		require.NoError(t, container.SingletonStruct(reflect.TypeFor[*greeterService](), As(reflect.TypeFor[greeter]())))
+
+		service, err := Get[*greeterService](container)
+		require.NoError(t, err)
+		bound, err := Get[greeter](container)
+		require.NoError(t, err)
+		require.Same(t, service, bound)
+	})
+	t.Run("should reject interfaces not implemented by the struct", func(t *testing.T) {
+		container := InitializeContainer(t)
+		err := container.SingletonStruct(reflect.TypeFor[greeterService](), As(reflect.TypeFor[greeter]()))
+		require.ErrorIs(t, err, wiringErrors.ErrInvalidResolver)
+		require.False(t, container.HasType(reflect.TypeFor[greeterService]()))
+	})
+	t.Run("should reject types that are not structs", func(t *testing.T) {
+		container := New()
+		require.ErrorIs(t, SingletonStruct[int](container), wiringErrors.ErrInvalidResolver)
+		require.ErrorIs(t, container.TransientStruct(nil), wiringErrors.ErrInvalidResolver)
+	})
+	t.Run("should return the error of the init hook", func(t *testing.T) {
+		container := InitializeContainer(t)
+		require.NoError(t, SingletonStruct[*failingService](container))
+
+		_, err := Get[*failingService](container)
+		require.ErrorIs(t, err, wiringErrors.ErrResolverFailed)
+		require.ErrorIs(t, err, errInitFailed)
+		require.EqualError(t, err, "initialization of *pkg.failingService failed: init failed")
+	})
+	t.Run("should validate, build and describe struct dependencies", func(t *testing.T) {
+		container := New()
+		require.NoError(t, SingletonStruct[*greeterService](container))
+		require.ErrorIs(t, container.Validate(), wiringErrors.ErrNotRegistered)
+
+		container = InitializeContainer(t)
+		require.NoError(t, SingletonStruct[*greeterService](container))
+		require.NoError(t, container.Build())
+		service, err := Get[*greeterService](container)
+		require.NoError(t, err)
+		require.NotNil(t, service.Greeter)
+
+		registrations := container.Registrations()
+		require.Equal(t, "*pkg.greeterService", registrations[0].Key)
+		require.Equal(t, "struct *pkg.greeterService", registrations[0].Signature)
+		require.Equal(t, []reflect.Type{reflect.TypeFor[mocks.Abstraction]()}, registrations[0].Dependencies)
+		require.Equal(t, []string{mocks.TESTING_TOKEN}, registrations[0].Tokens)
+	})
+}
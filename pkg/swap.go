@@ -0,0 +1,176 @@
+package pkg
+
+import (
+	"sync"
+	"time"
+)
+
+// ChangeEvent describes a dependency whose resolver has been swapped
+type ChangeEvent struct {
+	// Key is the name of the dependency, the type name or the quoted token
+	Key       string
+	LifeCycle abstractionLifeCycle
+}
+
+// subscription is a subscriber of the change events of a container
+type subscription struct {
+	notify func(event ChangeEvent)
+}
+
+// subscribers holds the subscriptions of a container
+type subscribers struct {
+	mutex         sync.Mutex
+	subscriptions []*subscription
+}
+
+// WithGracePeriod sets the time the previous singleton instance is kept alive once its resolver is
+// swapped or it is refreshed, so the resolutions that already received it can finish. By default it
+// is closed in the background right away.
+func WithGracePeriod(period time.Duration) Option {
+	return func(w *wireContainer) {
+		w.gracePeriod = period
+	}
+}
+
+// Swap implements pkg.Container.
+func (w *wireContainer) Swap(resolver any) error {
+	spec, err := newSpec(resolver, SINGLETON, w)
+	if err != nil {
+		return err
+	}
+	return w.swap(spec, func(r *registry) (*dependencySpec, error) {
+		return r.getSpec(spec.Type())
+	})
+}
+
+// SwapToken implements pkg.Container.
+func (w *wireContainer) SwapToken(token string, resolver any) error {
+	spec, err := newSpec(resolver, SINGLETON, w)
+	if err != nil {
+		return err
+	}
+	return w.swap(spec, func(r *registry) (*dependencySpec, error) {
+		return r.getSpecForToken(token)
+	})
+}
+
+// swap replaces every binding of the spec returned by previous with the new spec and publishes the
+// registry at once. Built containers validate the new registry and plan every resolver again since
+// the plans are bound to the previous spec.
+func (w *wireContainer) swap(spec *dependencySpec, previous func(r *registry) (*dependencySpec, error)) error {
+	if w.root != nil {
+		return ErrScopeRegistration
+	}
+	if w.isClosed() {
+		return ErrClosed
+	}
+
+	w.registryMutex.Lock()
+	updated := w.registry.Load().clone()
+	replaced, err := previous(updated)
+	if err != nil {
+		w.registryMutex.Unlock()
+		return err
+	}
+	err = updated.checkRebind(replaced, spec)
+	if err != nil {
+		w.registryMutex.Unlock()
+		return err
+	}
+	spec.inherit(replaced)
+
+	keys := updated.rebind(replaced, spec)
+
+	if w.frozen.Load() {
+		err = w.validate(updated)
+		if err != nil {
+			w.registryMutex.Unlock()
+			return err
+		}
+		for _, planned := range updated.specs() {
+			planned.plan.Store(updated.plan(planned))
+		}
+	}
+	w.registry.Store(updated)
+	w.registryMutex.Unlock()
+
+	// The dependents following the refreshes are rebuilt since the replaced spec stays stale
+	replaced.invalidated.Store(true)
+	w.retireSpec(keys[0], replaced)
+	for _, key := range keys {
+		w.subscribers.notify(ChangeEvent{Key: key, LifeCycle: spec.lifeCycle})
+	}
+	return nil
+}
+
+// retireSpec closes the singleton instance of a spec that is no longer registered once the
+// grace period is over
+func (w *wireContainer) retireSpec(key string, spec *dependencySpec) {
+	if spec.lifeCycle != SINGLETON {
+		return
+	}
+	w.afterGracePeriod(func() {
+		w.retired(spec, key, w.forget(spec))
+	})
+}
+
+// afterGracePeriod runs fn in the background once the grace period is over, right away if there
+// is none. It never runs fn in the caller so fn is outside the resolution that retires an instance.
+func (w *wireContainer) afterGracePeriod(fn func()) {
+	go func() {
+		if w.gracePeriod > 0 {
+			<-w.clock.After(w.gracePeriod)
+		}
+		fn()
+	}()
+}
+
+// forget stops tracking the instance of the spec so it is not closed with the container
+// and returns it, nil if the spec has no instance
+func (w *wireContainer) forget(spec *dependencySpec) any {
+	w.instancesMutex.Lock()
+	defer w.instancesMutex.Unlock()
+	for i, cached := range w.instances {
+		if cached.spec == spec {
+			w.instances = append(w.instances[:i], w.instances[i+1:]...)
+			return cached.instance
+		}
+	}
+	return nil
+}
+
+// Subscribe implements pkg.Container.
+func (w *wireContainer) Subscribe(subscriber func(event ChangeEvent)) (unsubscribe func()) {
+	container := w
+	if w.root != nil {
+		container = w.root
+	}
+	return container.subscribers.add(subscriber)
+}
+
+func (s *subscribers) add(notify func(event ChangeEvent)) func() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	subscription := &subscription{notify: notify}
+	s.subscriptions = append(s.subscriptions, subscription)
+
+	return func() {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		for i, subscribed := range s.subscriptions {
+			if subscribed == subscription {
+				s.subscriptions = append(s.subscriptions[:i:i], s.subscriptions[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (s *subscribers) notify(event ChangeEvent) {
+	s.mutex.Lock()
+	subscriptions := s.subscriptions
+	s.mutex.Unlock()
+	for _, subscription := range subscriptions {
+		subscription.notify(event)
+	}
+}
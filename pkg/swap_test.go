@@ -0,0 +1,181 @@
+package pkg
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	wiringErrors "github.com/4strodev/wiring/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type paymentGateway interface {
+	Name() string
+}
+
+type gateway struct {
+	name   string
+	closed atomic.Bool
+}
+
+func (g *gateway) Name() string {
+	return g.name
+}
+
+func (g *gateway) Close() error {
+	g.closed.Store(true)
+	return nil
+}
+
+// otherGateway implements paymentGateway but cannot replace a *gateway
+type otherGateway struct{}
+
+func (otherGateway) Name() string {
+	return "other"
+}
+
+type checkout struct {
+	gateway paymentGateway
+}
+
+func TestSwap(t *testing.T) {
+	newGateway := func(name string) func() *gateway {
+		return func() *gateway { return &gateway{name: name} }
+	}
+
+	t.Run("should swap the resolver while resolving concurrently", func(t *testing.T) {
+		container := New()
+		require.NoError(t, container.Singleton(newGateway("stripe"), As(reflect.TypeFor[paymentGateway]())))
+		require.NoError(t, container.Transient(func(g paymentGateway) *checkout { return &checkout{gateway: g} }))
+
+		var group sync.WaitGroup
+		for range 8 {
+			group.Add(1)
+			go func() {
+				defer group.Done()
+				for range 100 {
+					instance, err := Get[*checkout](container)
+					if err != nil {
+						t.Error(err)
+						return
+					}
+					if name := instance.gateway.Name(); name != "stripe" && name != "adyen" {
+						t.Errorf("unexpected gateway %s", name)
+					}
+				}
+			}()
+		}
+		require.NoError(t, container.Swap(newGateway("adyen")))
+		group.Wait()
+
+		instance, err := Get[*checkout](container)
+		require.NoError(t, err)
+		require.Equal(t, "adyen", instance.gateway.Name())
+	})
+
+	t.Run("should close the previous instance after the grace period", func(t *testing.T) {
+		container := New(WithGracePeriod(20 * time.Millisecond))
+		require.NoError(t, container.Singleton(newGateway("stripe")))
+		previous, err := Get[*gateway](container)
+		require.NoError(t, err)
+
+		require.NoError(t, container.Swap(newGateway("adyen")))
+		require.False(t, previous.closed.Load())
+		require.Eventually(t, previous.closed.Load, time.Second, time.Millisecond)
+
+		current, err := Get[*gateway](container)
+		require.NoError(t, err)
+		require.Equal(t, "adyen", current.name)
+		require.NoError(t, container.Close(context.Background()))
+		require.True(t, current.closed.Load())
+	})
+
+	t.Run("should rebuild the dependents following the refreshes", func(t *testing.T) {
+		container := New()
+		require.NoError(t, container.Singleton(newGateway("stripe")))
+		require.NoError(t, container.Singleton(func(g *gateway) *checkout {
+			return &checkout{gateway: g}
+		}, FollowRefresh()))
+		require.NoError(t, container.Build())
+		previous, err := Get[*checkout](container)
+		require.NoError(t, err)
+
+		require.NoError(t, container.Swap(newGateway("adyen")))
+		current, err := Get[*checkout](container)
+		require.NoError(t, err)
+		require.NotSame(t, previous, current)
+		require.Equal(t, "adyen", current.gateway.Name())
+		require.False(t, current.gateway.(*gateway).closed.Load())
+		cached, err := Get[*checkout](container)
+		require.NoError(t, err)
+		require.Same(t, current, cached)
+	})
+
+	t.Run("should reject resolvers that do not fit the bound types", func(t *testing.T) {
+		for _, build := range []bool{false, true} {
+			container := New()
+			require.NoError(t, container.Singleton(newGateway("stripe"), As(reflect.TypeFor[paymentGateway]())))
+			require.NoError(t, container.Transient(func(g *gateway) *checkout { return &checkout{gateway: g} }))
+			if build {
+				require.NoError(t, container.Build())
+			}
+
+			err := container.Swap(func() paymentGateway { return &otherGateway{} })
+			require.ErrorIs(t, err, wiringErrors.ErrWrongType)
+			instance, err := Get[*checkout](container)
+			require.NoError(t, err)
+			require.Equal(t, "stripe", instance.gateway.Name())
+		}
+	})
+
+	t.Run("should notify the swapped keys", func(t *testing.T) {
+		container := New()
+		require.NoError(t, container.Singleton(newGateway("stripe"), As(reflect.TypeFor[paymentGateway]())))
+		require.NoError(t, container.TransientToken("gateway", newGateway("stripe")))
+
+		var events []ChangeEvent
+		unsubscribe := container.NewScope().Subscribe(func(event ChangeEvent) {
+			events = append(events, event)
+		})
+		require.NoError(t, container.Swap(newGateway("adyen")))
+		require.NoError(t, container.SwapToken("gateway", newGateway("adyen")))
+		require.Equal(t, []ChangeEvent{
+			{Key: "*pkg.gateway", LifeCycle: SINGLETON},
+			{Key: "pkg.paymentGateway", LifeCycle: SINGLETON},
+			{Key: "'gateway'", LifeCycle: TRANSIENT},
+		}, events)
+
+		unsubscribe()
+		require.NoError(t, container.Swap(newGateway("paypal")))
+		require.Len(t, events, 3)
+	})
+
+	t.Run("should swap on a built container", func(t *testing.T) {
+		container := New()
+		require.NoError(t, container.Singleton(newGateway("stripe"), As(reflect.TypeFor[paymentGateway]())))
+		require.NoError(t, container.Transient(func(g paymentGateway) *checkout { return &checkout{gateway: g} }))
+		require.NoError(t, container.Build())
+
+		require.NoError(t, container.Swap(newGateway("adyen")))
+		instance, err := Get[*checkout](container)
+		require.NoError(t, err)
+		require.Equal(t, "adyen", instance.gateway.Name())
+
+		// The graph is validated before swapping
+		err = container.Swap(func(*counter) *gateway { return &gateway{name: "broken"} })
+		require.ErrorIs(t, err, wiringErrors.ErrNotRegistered)
+		instance, err = Get[*checkout](container)
+		require.NoError(t, err)
+		require.Equal(t, "adyen", instance.gateway.Name())
+	})
+
+	t.Run("should only swap registered dependencies", func(t *testing.T) {
+		container := New()
+		require.ErrorIs(t, container.Swap(newGateway("stripe")), wiringErrors.ErrNotRegistered)
+		require.ErrorIs(t, container.SwapToken("gateway", newGateway("stripe")), wiringErrors.ErrNotRegistered)
+		require.ErrorIs(t, container.NewScope().Swap(newGateway("stripe")), ErrScopeRegistration)
+	})
+}
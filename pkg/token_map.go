@@ -1,4 +1,17 @@
 package pkg
 
-// tokenMap is a concurrently save map that holds a dependency spec associated to a token
+import "sort"
+
+// tokenMap holds dependency specs associated to a token. It is part of a [registry]
+// so it must not be modified once the registry is published.
 type tokenMap map[string]*dependencySpec
+
+// sortedTokens returns the registered tokens sorted
+func (m tokenMap) sortedTokens() []string {
+	tokens := make([]string, 0, len(m))
+	for token := range m {
+		tokens = append(tokens, token)
+	}
+	sort.Strings(tokens)
+	return tokens
+}
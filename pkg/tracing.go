@@ -0,0 +1,69 @@
+package pkg
+
+import "context"
+
+// Tracer starts the spans of the resolutions. It follows the shape of an OpenTelemetry
+// tracer so it can be adapted with a few lines:
+//
+//	func (t otelTracer) Start(ctx context.Context, name string) (context.Context, wiring.Span) {
+//		ctx, span := t.tracer.Start(ctx, name)
+//		return ctx, otelSpan{span}
+//	}
+type Tracer interface {
+	// Start starts a span as a child of the span in the context, if any
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a span started by a [Tracer]
+type Span interface {
+	SetAttribute(key string, value any)
+	RecordError(err error)
+	End()
+}
+
+// Attributes set on the span of every resolution
+const (
+	// ATTRIBUTE_KEY is the key of the dependency, the type name or the quoted token
+	ATTRIBUTE_KEY = "wiring.key"
+	// ATTRIBUTE_LIFECYCLE is the lifecycle of the dependency
+	ATTRIBUTE_LIFECYCLE = "wiring.lifecycle"
+	// ATTRIBUTE_CACHE is "hit" when the instance was cached and "miss" when it was created
+	ATTRIBUTE_CACHE = "wiring.cache"
+)
+
+// WithTracer traces the resolutions of the container and its scopes. Every dependency
+// resolved opens a span named after its key, nested following the resolution chain. The
+// context passed to ResolveContext is the parent of the spans and resolvers receive the
+// context of their own span.
+func WithTracer(tracer Tracer) Option {
+	return func(w *wireContainer) {
+		w.tracer = tracer
+	}
+}
+
+// trace resolves the spec inside a span when the container has a tracer
+func (spec *dependencySpec) trace(res *resolution, key string) (any, bool, error) {
+	tracer := spec.container.tracer
+	if tracer == nil {
+		return spec.observe(res, key)
+	}
+
+	ctx := res.ctx
+	spanCtx, span := tracer.Start(ctx, key)
+	span.SetAttribute(ATTRIBUTE_KEY, key)
+	span.SetAttribute(ATTRIBUTE_LIFECYCLE, spec.lifeCycle.String())
+
+	res.ctx = spanCtx
+	instance, cached, err := spec.observe(res, key)
+	res.ctx = ctx
+
+	if err != nil {
+		span.RecordError(err)
+	} else if cached {
+		span.SetAttribute(ATTRIBUTE_CACHE, "hit")
+	} else {
+		span.SetAttribute(ATTRIBUTE_CACHE, "miss")
+	}
+	span.End()
+	return instance, cached, err
+}
@@ -1,6 +1,26 @@
 package pkg
 
-import "reflect"
+import (
+	"reflect"
+	"sort"
+)
 
-// typeMap is a concurrently save map that holds dependency specs associated to [reflect.Type]
+// typeMap holds dependency specs associated to [reflect.Type]. It is part of a [registry]
+// so it must not be modified once the registry is published.
 type typeMap map[reflect.Type]*dependencySpec
+
+// sortedTypes returns the registered types sorted by their name
+func (m typeMap) sortedTypes() []reflect.Type {
+	return sortedTypes(m)
+}
+
+func sortedTypes[V any](m map[reflect.Type]V) []reflect.Type {
+	types := make([]reflect.Type, 0, len(m))
+	for refType := range m {
+		types = append(types, refType)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return types[i].String() < types[j].String()
+	})
+	return types
+}
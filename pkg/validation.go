@@ -0,0 +1,162 @@
+package pkg
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/4strodev/wiring/pkg/errors"
+)
+
+// validator walks the dependency graph of a container collecting every problem found
+type validator struct {
+	registry *registry
+	visited  map[visitedSpec]bool
+	res      *resolution
+	problems []error
+}
+
+// Validate implements pkg.Container.
+func (w *wireContainer) Validate() error {
+	return w.validate(w.snapshot())
+}
+
+// validate checks the dependency graph of the registry
+func (w *wireContainer) validate(r *registry) error {
+	res := newResolution(context.Background(), w)
+	res.registry = r
+	v := &validator{
+		registry: res.registry,
+		visited:  make(map[visitedSpec]bool),
+		res:      res,
+	}
+
+	for _, refType := range v.registry.typeMapping.sortedTypes() {
+		v.visit(typeKey(refType), v.registry.typeMapping[refType], "")
+	}
+	for _, token := range v.registry.tokenMapping.sortedTokens() {
+		v.visit(tokenKey(token), v.registry.tokenMapping[token], "")
+	}
+	for _, refType := range v.registry.groupMapping.sortedTypes() {
+		for _, member := range v.registry.groupMapping[refType].members {
+			v.visit(groupKey(refType, member.name), member.spec, "")
+		}
+	}
+
+	v.visitDecorators()
+
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return errors.WrapError(stderrors.Join(v.problems...))
+}
+
+// visitedSpec is a spec visited by the validator. Transient specs are visited again when they are
+// created for a singleton since their dependencies are resolved outside the scope then.
+type visitedSpec struct {
+	spec      *dependencySpec
+	singleton bool
+}
+
+// visit checks the spec and its dependencies, singleton is the key of the singleton the spec is
+// created for, empty if there is none
+func (v *validator) visit(key string, spec *dependencySpec, singleton string) {
+	switch spec.lifeCycle {
+	case SINGLETON:
+		singleton = key
+	case SCOPED:
+		singleton = ""
+	}
+	visited := visitedSpec{spec: spec, singleton: singleton != ""}
+	if v.visited[visited] {
+		return
+	}
+
+	err := v.res.enter(key, spec)
+	if err != nil {
+		v.problems = append(v.problems, err)
+		return
+	}
+	defer v.res.leave()
+
+	v.visitDependencies(key, singleton, spec.dependencies(v.registry))
+	v.visited[visited] = true
+}
+
+// visitDependencies checks the dependencies required by the key. Dependencies requested through
+// a Lazy or a provider are not followed because they are resolved later, outside the chain.
+// Scoped dependencies are reported when the key is created for the singleton, even through
+// transient dependencies.
+func (v *validator) visitDependencies(key string, singleton string, dependencies []dependency) {
+	for _, dep := range dependencies {
+		dep = v.registry.resolvedDependency(dep)
+		var dependency *dependencySpec
+		var exists bool
+		var description string
+		if dep.token != "" {
+			dependency, exists = v.registry.tokenMapping[dep.token]
+			description = fmt.Sprintf("token '%s'", dep.token)
+		} else {
+			group, isGroup := v.registry.getGroup(dep.refType)
+			if isGroup {
+				if dep.deferredType == nil {
+					for _, member := range group.members {
+						v.visit(groupKey(dep.refType.Elem(), member.name), member.spec, singleton)
+					}
+				}
+				continue
+			}
+			dependency, exists = v.registry.typeMapping[dep.refType]
+			description = fmt.Sprintf("type '%s'", dep.refType.String())
+		}
+
+		if !exists {
+			if dep.optional {
+				continue
+			}
+			v.problems = append(v.problems, v.res.fail(errors.ErrNotRegistered, dep.key(), "resolver for %s requires %s which is not set", key, description))
+			continue
+		}
+		if singleton != "" && dependency.lifeCycle == SCOPED {
+			v.problems = append(v.problems, v.res.fail(errors.ErrOutOfScope, dep.key(), "singleton %s depends on scoped %s", singleton, description))
+			continue
+		}
+		if dep.deferredType == nil {
+			v.visit(dep.key(), dependency, singleton)
+		}
+	}
+}
+
+// visitDecorators checks that decorated keys are registered and that the dependencies
+// of their decorators can be resolved
+func (v *validator) visitDecorators() {
+	keys := slices.Sorted(maps.Keys(v.registry.decorators))
+	for _, key := range keys {
+		for _, d := range v.registry.decorators[key] {
+			var spec *dependencySpec
+			if d.token != "" {
+				spec = v.registry.tokenMapping[d.token]
+			} else {
+				spec = v.registry.typeMapping[d.decoratedType]
+			}
+			if spec == nil {
+				v.problems = append(v.problems, v.res.fail(errors.ErrNotRegistered, key, "%s decorates %s which is not set", d, key))
+				continue
+			}
+
+			err := v.res.enter(key, spec)
+			if err != nil {
+				v.problems = append(v.problems, err)
+				continue
+			}
+			singleton := ""
+			if spec.lifeCycle == SINGLETON {
+				singleton = key
+			}
+			v.visitDependencies(key, singleton, d.dependencies(v.registry))
+			v.res.leave()
+		}
+	}
+}
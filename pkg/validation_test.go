@@ -0,0 +1,58 @@
+package pkg
+
+import (
+	"io"
+	"testing"
+
+	"github.com/4strodev/wiring/pkg/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	t.Run("should accept a valid container", func(t *testing.T) {
+		container := InitializeContainer(t)
+		err := container.Singleton(func(abstraction mocks.Abstraction) ComplexAbstraction {
+			return ComplexImplementation{Abstraction: abstraction}
+		})
+		require.NoError(t, err)
+		require.NoError(t, container.Validate())
+	})
+	t.Run("should not execute resolvers", func(t *testing.T) {
+		container := New()
+		err := container.Singleton(func() mocks.Abstraction {
+			t.Fatal("resolver executed")
+			return nil
+		})
+		require.NoError(t, err)
+		require.NoError(t, container.Validate())
+	})
+	t.Run("should report every missing dependency", func(t *testing.T) {
+		container := New()
+		err := container.Singleton(func(abstraction mocks.Abstraction) ComplexAbstraction {
+			return ComplexImplementation{Abstraction: abstraction}
+		})
+		require.NoError(t, err)
+		err = container.TransientToken("reader", func(reader io.Reader) string {
+			return ""
+		})
+		require.NoError(t, err)
+
+		err = container.Validate()
+		require.EqualError(t, err, "resolver for pkg.ComplexAbstraction requires type 'mocks.Abstraction' which is not set\n"+
+			"resolver for 'reader' requires type 'io.Reader' which is not set")
+	})
+	t.Run("should report circular dependencies", func(t *testing.T) {
+		container := New()
+		err := container.Singleton(func(*cyclicRepo) *cyclicDB {
+			return &cyclicDB{}
+		})
+		require.NoError(t, err)
+		err = container.Singleton(func(*cyclicDB) *cyclicRepo {
+			return &cyclicRepo{}
+		})
+		require.NoError(t, err)
+
+		err = container.Validate()
+		require.EqualError(t, err, "circular dependency detected: *pkg.cyclicDB -> *pkg.cyclicRepo -> *pkg.cyclicDB")
+	})
+}
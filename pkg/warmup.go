@@ -0,0 +1,156 @@
+package pkg
+
+import (
+	"context"
+	stderrors "errors"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/4strodev/wiring/pkg/errors"
+)
+
+// Eager marks a singleton to be instantiated by WarmUp instead of on its first use
+func Eager() RegistrationOption {
+	return func(registration *registrationOptions) {
+		registration.eager = true
+	}
+}
+
+// warmUpNode is a spec instantiated by WarmUp once its dependencies are ready
+type warmUpNode struct {
+	key          string
+	spec         *dependencySpec
+	dependencies []*warmUpNode
+	done         chan struct{}
+	// failed is set when the node or one of its dependencies could not be instantiated
+	failed bool
+	// err is the error instantiating the node
+	err error
+}
+
+// warmUp builds the graph of the eager singletons. Cycles are broken so the nodes never wait
+// for each other, the node left waiting for nothing resolves the whole cycle and reports it.
+type warmUp struct {
+	registry *registry
+	nodes    map[*dependencySpec]*warmUpNode
+	// visiting holds the nodes on the current path of the graph
+	visiting map[*warmUpNode]bool
+}
+
+// WarmUp implements pkg.Container.
+func (w *wireContainer) WarmUp(ctx context.Context) error {
+	if w.isClosed() {
+		return ErrClosed
+	}
+
+	graph := &warmUp{
+		registry: w.snapshot(),
+		nodes:    make(map[*dependencySpec]*warmUpNode),
+		visiting: make(map[*warmUpNode]bool),
+	}
+	for _, refType := range graph.registry.typeMapping.sortedTypes() {
+		spec := graph.registry.typeMapping[refType]
+		if spec.eager {
+			graph.node(typeKey(refType), spec)
+		}
+	}
+	for _, token := range graph.registry.tokenMapping.sortedTokens() {
+		spec := graph.registry.tokenMapping[token]
+		if spec.eager {
+			graph.node(tokenKey(token), spec)
+		}
+	}
+
+	var group sync.WaitGroup
+	for _, node := range graph.nodes {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			node.err = node.instantiate(ctx, w)
+		}()
+	}
+	group.Wait()
+
+	// Problems are reported in the order of the keys so the error does not depend on scheduling
+	var problems []error
+	for _, node := range graph.sortedNodes() {
+		if node.err != nil {
+			problems = append(problems, errors.Errorf("error warming up %s: %w", node.key, node.err).WithKey(node.key))
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return errors.WrapError(stderrors.Join(problems...))
+}
+
+// sortedNodes returns the nodes of the graph sorted by key
+func (graph *warmUp) sortedNodes() []*warmUpNode {
+	nodes := slices.Collect(maps.Values(graph.nodes))
+	slices.SortFunc(nodes, func(a, b *warmUpNode) int {
+		return strings.Compare(a.key, b.key)
+	})
+	return nodes
+}
+
+// node returns the node of the spec adding it and its dependencies to the graph
+func (graph *warmUp) node(key string, spec *dependencySpec) *warmUpNode {
+	node, exists := graph.nodes[spec]
+	if exists {
+		if graph.visiting[node] {
+			return nil
+		}
+		return node
+	}
+
+	node = &warmUpNode{key: key, spec: spec, done: make(chan struct{})}
+	graph.nodes[spec] = node
+	graph.visiting[node] = true
+	defer delete(graph.visiting, node)
+
+	dependencies := spec.dependencies(graph.registry)
+	for _, d := range graph.registry.decorators[key] {
+		dependencies = append(dependencies, d.dependencies(graph.registry)...)
+	}
+	for _, dep := range dependencies {
+		// Missing and scoped dependencies are reported when the node is instantiated
+		for _, bound := range graph.registry.boundSpecs(dep) {
+			if bound.spec.lifeCycle == SCOPED {
+				continue
+			}
+			dependencyNode := graph.node(bound.key, bound.spec)
+			if dependencyNode != nil {
+				node.dependencies = append(node.dependencies, dependencyNode)
+			}
+		}
+	}
+	return node
+}
+
+// instantiate waits for the dependencies of the node and instantiates it when it is a singleton.
+// Nodes whose dependencies failed are skipped so only the cause is reported.
+func (node *warmUpNode) instantiate(ctx context.Context, w *wireContainer) error {
+	defer close(node.done)
+	for _, dependency := range node.dependencies {
+		<-dependency.done
+		if dependency.failed {
+			node.failed = true
+			return nil
+		}
+	}
+	if node.spec.lifeCycle != SINGLETON {
+		return nil
+	}
+
+	res := newResolution(ctx, w)
+	err := ctx.Err()
+	if err != nil {
+		node.failed = true
+		return res.fail(errors.ErrCancelled, node.key, "warm up of %s aborted: %w", node.key, err)
+	}
+	_, err = node.spec.resolve(res, node.key)
+	node.failed = err != nil
+	return err
+}
@@ -0,0 +1,138 @@
+package pkg
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	wiringErrors "github.com/4strodev/wiring/pkg/errors"
+	"github.com/4strodev/wiring/pkg/internal/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+type warmUpCache struct{}
+
+type warmUpQueue struct{}
+
+type warmUpServer struct{}
+
+type warmUpCycleA struct{}
+
+type warmUpCycleB struct{}
+
+func TestWarmUp(t *testing.T) {
+	t.Run("should instantiate only the eager singletons", func(t *testing.T) {
+		var created atomic.Int32
+		container := New()
+		require.NoError(t, container.Singleton(func() *counter {
+			created.Add(1)
+			return &counter{}
+		}, Eager()))
+		require.NoError(t, container.Singleton(mocks.Resolver))
+
+		require.NoError(t, container.WarmUp(context.Background()))
+		require.EqualValues(t, 1, created.Load())
+		registrations := container.Registrations()
+		for _, registration := range registrations {
+			require.Equal(t, registration.Key == "*pkg.counter", registration.Instantiated, registration.Key)
+		}
+
+		// Instances are already cached
+		_, err := Get[*counter](container)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, created.Load())
+	})
+
+	t.Run("should instantiate independent branches concurrently", func(t *testing.T) {
+		// Both dependencies wait for each other to be running, they only finish if they run concurrently
+		var cacheRunning, queueRunning sync.WaitGroup
+		cacheRunning.Add(1)
+		queueRunning.Add(1)
+		container := New()
+		require.NoError(t, container.Singleton(func() *warmUpCache {
+			cacheRunning.Done()
+			queueRunning.Wait()
+			return &warmUpCache{}
+		}))
+		require.NoError(t, container.Singleton(func() *warmUpQueue {
+			queueRunning.Done()
+			cacheRunning.Wait()
+			return &warmUpQueue{}
+		}))
+		require.NoError(t, container.Singleton(func(*warmUpCache, *warmUpQueue) *warmUpServer {
+			return &warmUpServer{}
+		}, Eager()))
+		require.NoError(t, container.Singleton(func(*warmUpCache) *counter { return &counter{} }, Eager()))
+
+		done := make(chan error)
+		go func() {
+			done <- container.WarmUp(context.Background())
+		}()
+		select {
+		case err := <-done:
+			require.NoError(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("branches were not instantiated concurrently")
+		}
+	})
+
+	t.Run("should aggregate the errors by key", func(t *testing.T) {
+		var serverCreated bool
+		container := New()
+		require.NoError(t, container.Singleton(func() (*warmUpCache, error) {
+			return nil, errors.New("connection refused")
+		}))
+		require.NoError(t, container.Singleton(func(*warmUpCache) *warmUpServer {
+			serverCreated = true
+			return &warmUpServer{}
+		}, Eager()))
+		require.NoError(t, container.Singleton(func(*warmUpQueue) *counter { return &counter{} }, Eager()))
+
+		err := container.WarmUp(context.Background())
+		require.Regexp(t, "(?s)^error warming up \\*pkg.counter: .*\nerror warming up \\*pkg.warmUpCache: .*connection refused", err.Error())
+		require.ErrorIs(t, err, wiringErrors.ErrNotRegistered)
+		// Dependents of failed dependencies are skipped
+		require.NotContains(t, err.Error(), "error warming up *pkg.warmUpServer")
+		require.False(t, serverCreated)
+	})
+
+	t.Run("should report circular dependencies", func(t *testing.T) {
+		container := New()
+		require.NoError(t, container.Singleton(func(*warmUpCycleB) *warmUpCycleA { return &warmUpCycleA{} }, Eager()))
+		require.NoError(t, container.Singleton(func(*warmUpCycleA) *warmUpCycleB { return &warmUpCycleB{} }, Eager()))
+
+		done := make(chan error)
+		go func() {
+			done <- container.WarmUp(context.Background())
+		}()
+		select {
+		case err := <-done:
+			require.ErrorIs(t, err, wiringErrors.ErrCircularDependency)
+		case <-time.After(time.Second):
+			t.Fatal("warm up is deadlocked")
+		}
+	})
+
+	t.Run("should skip the singletons once the context is cancelled", func(t *testing.T) {
+		container := New()
+		require.NoError(t, container.Singleton(func() *counter { return &counter{} }, Eager()))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := container.WarmUp(ctx)
+		require.ErrorIs(t, err, wiringErrors.ErrCancelled)
+		require.ErrorIs(t, err, context.Canceled)
+		require.False(t, container.Registrations()[0].Instantiated)
+	})
+
+	t.Run("should only accept eager singletons", func(t *testing.T) {
+		container := New()
+		err := container.Transient(mocks.Resolver, Eager())
+		require.ErrorIs(t, err, wiringErrors.ErrInvalidResolver)
+		require.False(t, container.HasType(reflect.TypeFor[mocks.Abstraction]()))
+	})
+}